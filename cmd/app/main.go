@@ -2,10 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"l0/internal/cache"
 	"l0/internal/config"
 	"l0/internal/handler"
+	"l0/internal/health"
 	"l0/internal/kafka"
+	"l0/internal/rules"
+	"l0/internal/schema"
 	"l0/internal/service"
 	pg "l0/internal/storage/postgres"
 	"l0/internal/storage/redis"
@@ -13,8 +18,10 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -28,27 +35,79 @@ type Components struct {
 	Postgres      *pg.Postgres
 	Redis         *redis.Redis
 	KafkaConsumer *kafka.KafkaConsumer
+	KafkaClient   sarama.Client
+	Supervisor    *kafka.Supervisor
+	DeadLetters   *kafka.DeadLetterQueue
+	Events        *kafka.EventProducer
+	Health        *health.Registry
 }
 
 func InitComponents(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*Components, error) {
 
-	redis, err := redis.NewRedis(&cfg.Redis, logger)
+	healthRegistry := health.NewRegistry()
+
+	var backend cache.Backend
+	var redisClient *redis.Redis
+	switch cfg.Redis.CacheBackend {
+	case "memory":
+		memory := cache.NewMemory()
+		go memory.RunSweeper(ctx, time.Minute)
+		backend = memory
+	default:
+		var err error
+		redisClient, err = redis.NewRedis(&cfg.Redis, logger)
+		if err != nil {
+			logger.Error("redis error", "error", err.Error())
+			return nil, fmt.Errorf("components.init.InitComponents.redis failed: %v", err)
+		}
+		healthRegistry.Register("redis", redisClient)
+		backend = redisClient
+	}
+
+	encoding, err := cache.ParseEncoding(cfg.Redis.CacheEncoding)
 	if err != nil {
-		logger.Error("redis error", "error", err.Error())
-		return nil, fmt.Errorf("components.init.InitComponents.redis failed: %v", err)
+		return nil, fmt.Errorf("components.init.InitComponents.cache failed: %w", err)
+	}
+	orderCache := cache.NewOrderCache(backend, cache.Options{
+		Encoding:          encoding,
+		CompressThreshold: cfg.Redis.CompressThreshold,
+		TTL:               cfg.Redis.CacheHardTTL,
+		SoftTTL:           cfg.Redis.CacheSoftTTL,
+	})
+	// готовые ответы живут не дольше мягкого TTL, чтобы не пережить обновление заказа
+	responseTTL := cfg.Redis.CacheHardTTL
+	if cfg.Redis.CacheSoftTTL > 0 {
+		responseTTL = cfg.Redis.CacheSoftTTL
 	}
+	responseCache := cache.NewOrderResponseCache(backend, cache.Options{
+		CompressThreshold: cfg.Redis.CompressThreshold,
+		TTL:               responseTTL,
+	})
 
-	postgres, err := pg.NewPostgres(ctx, cfg, logger, redis)
+	postgres, err := pg.NewPostgres(ctx, cfg, logger, orderCache)
 	if err != nil {
 		logger.Error("postgres error", "error", err.Error())
 		return nil, fmt.Errorf("components.init.InitComponents.postgres failed: %w", err)
 	}
 
-	saramaConfig := sarama.NewConfig()
-	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
-	saramaConfig.Consumer.Return.Errors = true
+	// events остаётся nil-интерфейсом, если топик событий не задан
+	var events service.EventPublisher
+	var eventProducer *kafka.EventProducer
+	if cfg.Kafka.EventsTopic != "" {
+		producerConfig, err := kafka.NewEventProducerConfig(cfg.Kafka)
+		if err != nil {
+			return nil, fmt.Errorf("components.init.InitComponents.events failed: %w", err)
+		}
+		producer, err := sarama.NewAsyncProducer(cfg.Kafka.BrokerList, producerConfig)
+		if err != nil {
+			logger.Error("components.init.InitComponents.events: failed to create events producer", "error", err.Error())
+			return nil, fmt.Errorf("components.init.InitComponent: events producer failed to init: %w", err)
+		}
+		eventProducer = kafka.NewEventProducer(producer, cfg.Kafka.EventsTopic, logger)
+		events = eventProducer
+	}
 
-	orderService := service.NewService(logger, postgres, redis)
+	orderService := service.NewService(logger, postgres, orderCache, events)
 
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -59,36 +118,129 @@ func InitComponents(ctx context.Context, cfg *config.Config, logger *slog.Logger
 
 	render := service.New(cwd+"/templates", logger)
 
-	consumer, err := sarama.NewConsumer(cfg.Kafka.BrokerList, saramaConfig)
+	// клиент нужен отдельно от группы, чтобы искать смещения по времени
+	consumerConfig, err := kafka.NewSaramaConfig(cfg.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("components.init.InitComponents.consumer failed: %w", err)
+	}
+	kafkaClient, err := sarama.NewClient(cfg.Kafka.BrokerList, consumerConfig)
+	if err != nil {
+		logger.Error("components.init.InitComponents.consumer: failed to create kafka client", "error", err.Error())
+		return nil, fmt.Errorf("components.init.InitComponent: kafka client failed to init: %w", err)
+	}
+	consumerGroup, err := sarama.NewConsumerGroupFromClient(cfg.Kafka.ConsumerGroup, kafkaClient)
+	if err != nil {
+		logger.Error("components.init.InitComponents.consumer: failed to create consumer group", "error", err.Error())
+		return nil, fmt.Errorf("components.init.InitComponent: consumer group failed to init: %w", err)
+	}
+	dlqConfig, err := kafka.NewProducerConfig(cfg.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("components.init.InitComponents.dlq failed: %w", err)
+	}
+	dlqProducer, err := sarama.NewSyncProducer(cfg.Kafka.BrokerList, dlqConfig)
+	if err != nil {
+		logger.Error("components.init.InitComponents.dlq: failed to create dlq producer", "error", err.Error())
+		return nil, fmt.Errorf("components.init.InitComponent: dlq producer failed to init: %w", err)
+	}
+	deadLetters := kafka.NewDeadLetterQueue(dlqProducer, cfg.Kafka.DLQTopic)
+	var registry schema.Registry
+	switch {
+	case cfg.Kafka.SchemaRegistryURL != "":
+		registry = schema.NewHTTPRegistry(cfg.Kafka.SchemaRegistryURL)
+	case cfg.Kafka.SchemaRegistryDir != "":
+		fileRegistry, err := schema.NewFileRegistry(cfg.Kafka.SchemaRegistryDir)
+		if err != nil {
+			return nil, fmt.Errorf("components.init.InitComponents.schema failed: %w", err)
+		}
+		registry = fileRegistry
+	}
+	decoders, err := kafka.NewDecoders(registry)
+	if err != nil {
+		return nil, fmt.Errorf("components.init.InitComponents.decoders failed: %w", err)
+	}
+	orderRules, err := rules.NewEngine(cfg.Rules.File, logger)
 	if err != nil {
-		logger.Error("components.init.InitComponents.consumer: failed to create consumer client", "error", err.Error())
-		return nil, fmt.Errorf("components.init.InitComponent: consumer client failed to init: %w", err)
+		return nil, fmt.Errorf("components.init.InitComponents.rules failed: %w", err)
+	}
+	go orderRules.Watch(ctx, cfg.Rules.ReloadInterval)
+	pipeline := kafka.NewPipeline(orderService, decoders, orderRules)
+
+	handlers := kafka.NewHandlers()
+	handlers.Register(cfg.Kafka.Topic, kafka.NewOrderHandler(pipeline))
+	for topic, name := range cfg.Kafka.TopicHandlers {
+		switch name {
+		case kafka.HandlerPayment:
+			handlers.Register(topic, kafka.NewPaymentHandler(postgres))
+		case kafka.HandlerDeliveryStatus:
+			handlers.Register(topic, kafka.NewDeliveryStatusHandler(postgres))
+		}
+	}
+	var retries *kafka.RetryScheduler
+	if len(cfg.Kafka.RetryDelays) > 0 {
+		// повторы публикуются тем же продюсером, что и DLQ
+		retries = kafka.NewRetryScheduler(dlqProducer, handlers.Topics(), cfg.Kafka.RetryDelays)
 	}
-	kafkaConsumer := kafka.NewKafkaConsumer(*cfg, logger, consumer, orderService)
 
-	httpServer := handler.NewServer(ctx, cfg, logger, postgres, redis, render)
+	// offsets остаётся nil-интерфейсом, если позиции хранятся только в Kafka
+	var offsets kafka.OffsetStore
+	if cfg.Kafka.ExactlyOnce {
+		offsets = postgres
+	}
+	// карантин идемпотентен и идёт первым: сбой любого приёмника не приводит
+	// к дублям в топике DLQ при повторной доставке
+	kafkaConsumer := kafka.NewKafkaConsumer(*cfg, logger, consumerGroup, handlers,
+		kafka.DeadLetterPublishers{kafka.NewQuarantineSink(postgres), deadLetters}, retries, offsets)
+	quarantine := service.NewQuarantine(logger, postgres, handlers)
+	consumerControl := kafka.NewConsumerControl(kafkaConsumer, kafkaClient)
+
+	healthRegistry.Register("postgres", postgres)
+	supervisor := kafka.NewSupervisor(cfg.Kafka, logger, kafkaConsumer)
+	healthRegistry.Register("kafka", supervisor)
+	prometheus.MustRegister(kafkaConsumer.Metrics())
+
+	httpServer := handler.NewServer(ctx, cfg, logger, orderService, orderCache, responseCache, orderCache, quarantine, consumerControl, orderRules, healthRegistry, render)
 
 	return &Components{
 		Postgres:      postgres,
-		Redis:         redis,
+		Redis:         redisClient,
 		KafkaConsumer: kafkaConsumer,
+		KafkaClient:   kafkaClient,
+		Supervisor:    supervisor,
+		DeadLetters:   deadLetters,
+		Events:        eventProducer,
 		HttpServer:    httpServer,
+		Health:        healthRegistry,
 	}, nil
 }
 
 func (c *Components) Shutdown() error {
 	var errs []error
 	c.Postgres.CloseConnection()
-	if err := c.Redis.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to close redis client: %w", err))
+	if c.Redis != nil {
+		if err := c.Redis.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close redis client: %w", err))
+		}
 	}
 	if err := c.KafkaConsumer.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to close kafka client: %w", err))
 	}
+	// группа, созданная из клиента, не закрывает его сама
+	if err := c.KafkaClient.Close(); err != nil && !errors.Is(err, sarama.ErrClosedClient) {
+		errs = append(errs, fmt.Errorf("failed to close kafka client: %w", err))
+	}
+	if err := c.DeadLetters.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close dlq producer: %w", err))
+	}
 
 	if err := c.HttpServer.Stop(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to close Http Server: %v", err))
 	}
+	// после остановки HTTP новых событий нет: дожидаемся отправки накопленных
+	if c.Events != nil {
+		if err := c.Events.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close events producer: %w", err))
+		}
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
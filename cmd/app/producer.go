@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"l0/internal/config"
+	"l0/internal/kafka"
+	"l0/internal/producer"
+	"l0/internal/schema"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/joho/godotenv"
+)
+
+// RunProducer подкоманда producer: отправляет заказы из файла или
+// сгенерированные заказы в топик и печатает итоги доставки
+//
+//	main producer -file model.json
+//	main producer -generate 1000 -rate 50 -keys random
+func RunProducer(args []string, out io.Writer) error {
+	_ = godotenv.Load()
+
+	fs := flag.NewFlagSet("producer", flag.ContinueOnError)
+	fs.SetOutput(out)
+	brokers := fs.String("brokers", os.Getenv("KAFKA_BROKER_LIST"), "брокеры Kafka через запятую")
+	topic := fs.String("topic", os.Getenv("KAFKA_TOPIC"), "топик заказов")
+	file := fs.String("file", "", "JSON или NDJSON файл с заказами, - для stdin")
+	generate := fs.Int("generate", 0, "сгенерировать N случайных корректных заказов")
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), "seed генератора")
+	rate := fs.Float64("rate", 0, "сообщений в секунду, 0 — без ограничения")
+	keys := fs.String("keys", producer.KeyOrderUID, "ключ сообщения: uid, random или none")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *brokers == "" || *topic == "" {
+		return fmt.Errorf("brokers and topic are required")
+	}
+	if (*file == "") == (*generate <= 0) {
+		return fmt.Errorf("exactly one of -file or -generate is required")
+	}
+	if err := producer.ValidateKeys(*keys); err != nil {
+		return err
+	}
+
+	var msgs []json.RawMessage
+	opts := producer.Options{Topic: *topic, Rate: *rate, Keys: *keys}
+	if *file != "" {
+		r := io.Reader(os.Stdin)
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		var err error
+		if msgs, err = producer.ReadMessages(r); err != nil {
+			return fmt.Errorf("read %s: %w", *file, err)
+		}
+	} else {
+		// сгенерированные заказы всегда в текущей версии схемы
+		opts.Headers = map[string]string{kafka.HeaderSchemaVersion: strconv.Itoa(schema.OrderVersion)}
+		gen := producer.NewGenerator(*seed)
+		msgs = make([]json.RawMessage, *generate)
+		for i := range msgs {
+			b, err := json.Marshal(gen.Order())
+			if err != nil {
+				return err
+			}
+			msgs[i] = b
+		}
+	}
+
+	clientConfig, err := config.LoadKafkaClient()
+	if err != nil {
+		return err
+	}
+	sc, err := kafka.NewLoadProducerConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	p, err := sarama.NewAsyncProducer(strings.Split(*brokers, ","), sc)
+	if err != nil {
+		return fmt.Errorf("create producer: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := producer.Publish(ctx, p, msgs, opts)
+	printReport(out, *topic, len(msgs), report)
+	if err != nil {
+		return err
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d of %d messages were not delivered", len(report.Failed), report.Sent)
+	}
+	return nil
+}
+
+func printReport(out io.Writer, topic string, total int, r producer.Report) {
+	fmt.Fprintf(out, "topic %s: sent %d/%d, delivered %d, failed %d in %s",
+		topic, r.Sent, total, r.Delivered, len(r.Failed), r.Duration.Round(time.Millisecond))
+	if secs := r.Duration.Seconds(); secs > 0 {
+		fmt.Fprintf(out, " (%.1f msg/s)", float64(r.Delivered)/secs)
+	}
+	fmt.Fprintln(out)
+
+	partitions := make([]int32, 0, len(r.Partitions))
+	for p := range r.Partitions {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	for _, p := range partitions {
+		fmt.Fprintf(out, "  partition %d: %d\n", p, r.Partitions[p])
+	}
+	for _, f := range r.Failed {
+		fmt.Fprintf(out, "  failed #%d key=%q: %v\n", f.Index, f.Key, f.Err)
+	}
+}
@@ -15,6 +15,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "producer" {
+		if err := app.RunProducer(os.Args[2:], os.Stdout); err != nil {
+			log.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -44,18 +52,27 @@ func main() {
 		}
 	}()
 
-	// Запускаем Kafka consumer
+	// Запускаем Kafka consumer под надзором: ошибка означает, что политика
+	// требует завершить процесс
+	consumerFailed := make(chan struct{})
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := comp.KafkaConsumer.Consume(ctx); err != nil {
+		if err := comp.Supervisor.Run(ctx); err != nil {
 			logger.Error("Kafka consumer failed", slog.String("error", err.Error()))
+			close(consumerFailed)
 		}
 	}()
 
-	// Ждём сигнал завершения
-	<-sigQuit
-	logger.Info("Received shutdown signal, stopping...")
+	// Ждём сигнал завершения или отказ консьюмера
+	exitCode := 0
+	select {
+	case <-sigQuit:
+		logger.Info("Received shutdown signal, stopping...")
+	case <-consumerFailed:
+		logger.Info("Kafka consumer stopped by failure policy, stopping...")
+		exitCode = 1
+	}
 
 	// Отменяем контекст, чтобы все горутины начали завершение
 	cancel()
@@ -69,4 +86,7 @@ func main() {
 	wg.Wait()
 
 	logger.Info("The program has exited")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
@@ -15,35 +15,275 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/orders": {
+        "/admin/cache/orders": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Удаляет все ключи пространства заказов (в Redis через SCAN), не затрагивая остальные данные",
+                "summary": "Очистить кэш заказов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CacheEvictResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает сохранённое в кэше значение заказа, его TTL и формат записи",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Просмотр заказа в кэше",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cache.Entry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "summary": "Удалить заказ из кэша",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CacheEvictResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/stats": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает количество попаданий, промахов, ошибок и долю попаданий по слоям кэша",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Статистика кэша",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CacheStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/consumer": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Позиция, отставание и состояние каждой партиции, назначенной этому экземпляру",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Состояние чтения Kafka",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerStateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/consumer/pause": {
             "post": {
-                "description": "Создаёт заказ с переданными данными",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Создать новый заказ",
+                "summary": "Приостановить чтение партиций",
                 "parameters": [
                     {
-                        "description": "Данные заказа",
-                        "name": "order",
+                        "description": "Топик и партиции",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Order"
+                            "$ref": "#/definitions/handler.ConsumerPartitionsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "ID созданного заказа",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/handler.ConsumerStateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/consumer/replay": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Перематывает партиции на первое сообщение не раньше timestamp (RFC 3339)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Перечитать сообщения с момента времени",
+                "parameters": [
+                    {
+                        "description": "Топик, партиции и время",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerSeekResponse"
                         }
                     },
                     "400": {
@@ -52,6 +292,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -61,261 +313,1436 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}": {
-            "get": {
-                "description": "Возвращает заказ по уникальному идентификатору",
-                "summary": "Получить заказ по ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID заказа",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handler.OrderResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
+        "/admin/consumer/resume": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Возобновить чтение партиций",
+                "parameters": [
+                    {
+                        "description": "Топик и партиции",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerPartitionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerStateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/consumer/seek": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Чтение партиции продолжится с указанного смещения после перезапуска сессии группы",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Перемотать партицию",
+                "parameters": [
+                    {
+                        "description": "Партиция и смещение",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerSeekRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ConsumerSeekResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает необработанные сообщения, новые первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список сообщений в карантине",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: pending, replaying, replayed, discarded",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Этап ошибки: decode, validate, persist",
+                        "name": "stage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Исходный топик",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuarantineListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/replay": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторно обработать несколько сообщений",
+                "parameters": [
+                    {
+                        "description": "ID сообщений",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuarantineReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuarantineReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает исходное тело сообщения, причину ошибки и нарушения проверки",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Сообщение из карантина",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сообщения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuarantinedMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{id}/discard": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Отбросить сообщение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сообщения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuarantineDiscardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{id}/payload": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Заменяет тело ожидающего сообщения; тело запроса сохраняется как есть",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Исправить тело сообщения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сообщения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Исправленный заказ",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuarantinedMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quarantine/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Пропускает сообщение через обычный путь обработки заказов. При неудаче сообщение остаётся в карантине, а ответ содержит причину.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторно обработать сообщение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сообщения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rules": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Правила из RULES_FILE и ошибка последней перезагрузки файла, если она была",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Действующие правила проверки заказов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rules.Status"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rules/dry-run": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Проверяет заказ правилами из запроса или действующими, ничего не сохраняя. Встроенные проверки заказа не выполняются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Пробная проверка заказа правилами",
+                "parameters": [
+                    {
+                        "description": "Заказ и правила",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RulesDryRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rules.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Возвращает состояние компонентов. 503, если хотя бы один компонент недоступен",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Состояние сервиса",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/order": {
+            "post": {
+                "description": "Создаёт заказ с переданными данными",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создать новый заказ",
+                "parameters": [
+                    {
+                        "description": "Данные заказа",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ID созданного заказа",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Заказ не согласован или нарушает правила Entry",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "Возвращает заказ по уникальному идентификатору",
+                "summary": "Получить заказ по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ранее полученного ответа",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.OrderResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Хэш тела ответа"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Заказ не изменился"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "cache.Entry": {
+            "type": "object",
+            "properties": {
+                "compressed": {
+                    "type": "boolean"
+                },
+                "decode_error": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "soft_expires_at": {
+                    "type": "string"
+                },
+                "ttl_ms": {
+                    "type": "integer"
+                },
+                "value": {}
+            }
+        },
+        "cache.LayerStats": {
+            "type": "object",
+            "properties": {
+                "bypassed": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "hit_ratio": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "layer": {
+                    "type": "string"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Delivery": {
+            "type": "object",
+            "required": [
+                "address",
+                "city",
+                "email",
+                "name",
+                "phone",
+                "region",
+                "zip"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "zip": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Items": {
+            "type": "object",
+            "required": [
+                "brand",
+                "chrt_id",
+                "name",
+                "nm_id",
+                "price",
+                "rid",
+                "total_price"
+            ],
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "chrt_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nm_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "rid": {
+                    "type": "string"
+                },
+                "sale": {
+                    "description": "Assuming sale is a percentage",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "size": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.Order": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "delivery",
+                "delivery_service",
+                "entry",
+                "items",
+                "locale",
+                "payment",
+                "shardkey",
+                "sm_id",
+                "track_number"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "delivery": {
+                    "description": "Add validation for Delivery struct",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Delivery"
+                        }
+                    ]
+                },
+                "delivery_service": {
+                    "type": "string"
+                },
+                "entry": {
+                    "type": "string"
+                },
+                "internal_signature": {
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Dive into the slice and validate each item",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Items"
+                    }
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "payment": {
+                    "$ref": "#/definitions/domain.Payment"
+                },
+                "shardkey": {
+                    "type": "string"
+                },
+                "sm_id": {
+                    "type": "integer"
+                },
+                "track_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Payment": {
+            "type": "object",
+            "required": [
+                "amount",
+                "bank",
+                "currency",
+                "delivery_cost",
+                "goods_total",
+                "payment_dt",
+                "provider",
+                "transaction"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "bank": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Assuming currency is a 3-letter code",
+                    "type": "string"
+                },
+                "custom_fee": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "delivery_cost": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "goods_total": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "payment_dt": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.QuarantineStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "replaying",
+                "replayed",
+                "discarded"
+            ],
+            "x-enum-varnames": [
+                "QuarantinePending",
+                "QuarantineReplaying",
+                "QuarantineReplayed",
+                "QuarantineDiscarded"
+            ]
+        },
+        "domain.QuarantinedMessage": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "discard_reason": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.QuarantineStatus"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Violation"
+                    }
+                }
+            }
+        },
+        "domain.Violation": {
+            "type": "object",
+            "properties": {
+                "actual": {},
+                "expected": {},
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CacheEvictResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CacheStatsResponse": {
+            "type": "object",
+            "properties": {
+                "layers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cache.LayerStats"
+                    }
+                }
+            }
+        },
+        "handler.ConsumerPartitionsRequest": {
+            "type": "object",
+            "required": [
+                "topic"
+            ],
+            "properties": {
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ConsumerReplayRequest": {
+            "type": "object",
+            "required": [
+                "timestamp",
+                "topic"
+            ],
+            "properties": {
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ConsumerSeekRequest": {
+            "type": "object",
+            "required": [
+                "offset",
+                "partition",
+                "topic"
+            ],
+            "properties": {
+                "offset": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ConsumerSeekResponse": {
+            "type": "object",
+            "properties": {
+                "offsets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ConsumerStateResponse": {
+            "type": "object",
+            "properties": {
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/kafka.PartitionState"
+                    }
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
+                }
+            }
+        },
+        "handler.QuarantineDiscardRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.QuarantineListResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.QuarantinedMessage"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Delivery": {
+        },
+        "handler.QuarantineReplayRequest": {
             "type": "object",
             "required": [
-                "address",
-                "city",
-                "email",
-                "name",
-                "phone",
-                "region",
-                "zip"
+                "ids"
             ],
             "properties": {
-                "address": {
-                    "type": "string"
+                "ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handler.QuarantineReplayResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ReplayResult"
+                    }
+                }
+            }
+        },
+        "handler.RulesDryRunRequest": {
+            "type": "object",
+            "properties": {
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
                 },
-                "city": {
+                "rules": {
+                    "description": "YAML с правилами; пустой — проверка действующими правилами",
                     "type": "string"
-                },
-                "email": {
+                }
+            }
+        },
+        "handler.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Violation"
+                    }
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
                 },
-                "phone": {
-                    "type": "string"
+                "status": {
+                    "$ref": "#/definitions/health.Status"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
                 },
-                "region": {
+                "error": {
                     "type": "string"
                 },
-                "zip": {
-                    "type": "string"
+                "status": {
+                    "$ref": "#/definitions/health.Status"
                 }
             }
         },
-        "domain.Items": {
-            "type": "object",
-            "required": [
-                "brand",
-                "chrt_id",
-                "name",
-                "nm_id",
-                "price",
-                "rid",
-                "total_price"
+        "health.Status": {
+            "type": "string",
+            "enum": [
+                "up",
+                "degraded",
+                "down"
             ],
+            "x-enum-varnames": [
+                "StatusUp",
+                "StatusDegraded",
+                "StatusDown"
+            ]
+        },
+        "kafka.PartitionState": {
+            "type": "object",
             "properties": {
-                "brand": {
-                    "type": "string"
+                "high_water_mark": {
+                    "type": "integer"
                 },
-                "chrt_id": {
+                "lag": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "offset": {
+                    "description": "Offset следующее сообщение к обработке: всё до него обработано",
+                    "type": "integer"
                 },
-                "nm_id": {
+                "partition": {
                     "type": "integer"
                 },
-                "price": {
-                    "type": "integer",
-                    "minimum": 0
+                "pending_seek": {
+                    "description": "PendingSeek смещение, с которого партиция будет читаться после\nперезапуска сессии",
+                    "type": "integer"
                 },
-                "rid": {
+                "state": {
                     "type": "string"
                 },
-                "sale": {
-                    "description": "Assuming sale is a percentage",
-                    "type": "integer",
-                    "maximum": 100,
-                    "minimum": 0
-                },
-                "size": {
+                "topic": {
                     "type": "string"
+                }
+            }
+        },
+        "rules.Report": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean"
                 },
-                "total_price": {
-                    "type": "integer",
-                    "minimum": 0
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rules.Result"
+                    }
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Violation"
+                    }
                 }
             }
         },
-        "domain.Order": {
+        "rules.Result": {
             "type": "object",
-            "required": [
-                "customer_id",
-                "delivery",
-                "delivery_service",
-                "entry",
-                "items",
-                "locale",
-                "payment",
-                "shardkey",
-                "sm_id",
-                "track_number"
-            ],
             "properties": {
-                "customer_id": {
-                    "type": "string"
+                "applied": {
+                    "description": "Applied правило подходит по Entry и условию when",
+                    "type": "boolean"
                 },
-                "delivery": {
-                    "description": "Add validation for Delivery struct",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.Delivery"
-                        }
-                    ]
+                "passed": {
+                    "type": "boolean"
                 },
-                "delivery_service": {
+                "rule": {
                     "type": "string"
                 },
-                "entry": {
+                "violation": {
+                    "$ref": "#/definitions/domain.Violation"
+                }
+            }
+        },
+        "rules.Rule": {
+            "type": "object",
+            "properties": {
+                "assert": {
+                    "description": "Assert условие, которому должен удовлетворять заказ",
                     "type": "string"
                 },
-                "internal_signature": {
+                "description": {
                     "type": "string"
                 },
-                "items": {
-                    "description": "Dive into the slice and validate each item",
+                "entries": {
+                    "description": "Entries маркетплейсы, к которым применяется правило; пустой — ко всем",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.Items"
+                        "type": "string"
                     }
                 },
-                "locale": {
+                "field": {
+                    "description": "Field проверяемое поле, его значение попадает в нарушение как actual",
                     "type": "string"
                 },
-                "order_uid": {
+                "id": {
+                    "description": "ID попадает в причину отказа, должен быть уникальным",
                     "type": "string"
                 },
-                "payment": {
-                    "$ref": "#/definitions/domain.Payment"
-                },
-                "shardkey": {
+                "message": {
                     "type": "string"
                 },
-                "sm_id": {
-                    "type": "integer"
-                },
-                "track_number": {
+                "when": {
+                    "description": "When условие применения правила, необязательное",
                     "type": "string"
                 }
             }
         },
-        "domain.Payment": {
+        "rules.Status": {
             "type": "object",
-            "required": [
-                "amount",
-                "bank",
-                "currency",
-                "delivery_cost",
-                "goods_total",
-                "payment_dt",
-                "provider",
-                "transaction"
-            ],
             "properties": {
-                "amount": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "bank": {
+                "error": {
+                    "description": "Error ошибка последней перезагрузки; при ней действуют прежние правила",
                     "type": "string"
                 },
-                "currency": {
-                    "description": "Assuming currency is a 3-letter code",
+                "file": {
                     "type": "string"
                 },
-                "delivery_cost": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "goods_total": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "payment_dt": {
-                    "type": "integer"
-                },
-                "provider": {
+                "loaded_at": {
                     "type": "string"
                 },
-                "transaction": {
-                    "type": "string"
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rules.Rule"
+                    }
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "service.ReplayResult": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.QuarantineStatus"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Violation"
+                    }
                 }
             }
-        },
-        "handler.OrderResponse": {
-            "type": "object",
-            "properties": {
-                "order": {
-                    "$ref": "#/definitions/domain.Order"
-                }
-            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "X-Admin-Token",
+            "in": "header"
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1",
 	Host:             "",
 	BasePath:         "",
 	Schemes:          []string{},
-	Title:            "",
+	Title:            "OrderService App Api",
 	Description:      "",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
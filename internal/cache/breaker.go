@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается, пока автомат разомкнут и обращения к кэшу пропускаются
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State состояние автоматического выключателя
+type State int32
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig параметры автоматического выключателя
+type BreakerConfig struct {
+	// FailureThreshold число подряд идущих ошибок, после которого автомат размыкается
+	FailureThreshold int
+	// OpenTimeout время в разомкнутом состоянии до перехода в half-open
+	OpenTimeout time.Duration
+	// HalfOpenProbes число успешных пробных запросов для замыкания
+	HalfOpenProbes int
+}
+
+// BreakerSnapshot текущее состояние выключателя для health check и админки
+type BreakerSnapshot struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker автоматический выключатель: после серии ошибок перестаёт пропускать
+// запросы на OpenTimeout, затем пропускает ограниченное число пробных.
+type Breaker struct {
+	cfg      BreakerConfig
+	onChange func(from, to State)
+	now      func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	inFlight  int
+	successes int
+}
+
+// NewBreaker создаёт замкнутый выключатель. onChange вызывается при смене
+// состояния под внутренней блокировкой и не должен обращаться к Breaker.
+func NewBreaker(cfg BreakerConfig, onChange func(from, to State)) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	return &Breaker{
+		cfg:      cfg,
+		onChange: onChange,
+		now:      time.Now,
+	}
+}
+
+// Execute выполняет fn, если автомат пропускает запрос, и учитывает результат.
+// Ошибки, для которых isFailure возвращает false, считаются успехом.
+func (b *Breaker) Execute(fn func() error, isFailure func(error) bool) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err != nil && (isFailure == nil || isFailure(err)))
+	return err
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+		fallthrough
+	case StateHalfOpen:
+		if b.inFlight >= b.cfg.HalfOpenProbes {
+			return ErrCircuitOpen
+		}
+		b.inFlight++
+	}
+	return nil
+}
+
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		if b.inFlight > 0 {
+			b.inFlight--
+		}
+		if failed {
+			b.failures++
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			b.failures = 0
+			b.setState(StateClosed)
+		}
+	case StateOpen:
+		// результат запроса, начатого до размыкания, не меняет состояние
+	}
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+func (b *Breaker) setState(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	b.inFlight = 0
+	b.successes = 0
+	if b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snap := BreakerSnapshot{
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		snap.OpenedAt = &openedAt
+	}
+	return snap
+}
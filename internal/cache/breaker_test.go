@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errBackend = errors.New("backend down")
+
+func newTestBreaker(now *time.Time) (*Breaker, *[]string) {
+	var transitions []string
+	b := NewBreaker(BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenProbes: 1},
+		func(from, to State) { transitions = append(transitions, from.String()+"->"+to.String()) })
+	b.now = func() time.Time { return *now }
+	return b, &transitions
+}
+
+func fail() error    { return errBackend }
+func succeed() error { return nil }
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	b, transitions := newTestBreaker(&now)
+
+	assert.ErrorIs(t, b.Execute(fail, nil), errBackend)
+	assert.ErrorIs(t, b.Execute(fail, nil), errBackend)
+	assert.NoError(t, b.Execute(succeed, nil), "success resets the counter")
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, b.Execute(fail, nil), errBackend)
+	}
+	assert.Equal(t, StateOpen, b.State())
+
+	called := false
+	err := b.Execute(func() error { called = true; return nil }, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.False(t, called, "open breaker must not call the backend")
+	assert.Equal(t, []string{"closed->open"}, *transitions)
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	b, transitions := newTestBreaker(&now)
+	for i := 0; i < 3; i++ {
+		_ = b.Execute(fail, nil)
+	}
+
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, b.Execute(fail, nil), errBackend)
+	assert.Equal(t, StateOpen, b.State(), "failed probe reopens the breaker")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Execute(succeed, nil))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, *transitions)
+}
+
+func TestBreaker_IgnoredErrors(t *testing.T) {
+	now := time.Now()
+	b, _ := newTestBreaker(&now)
+	notFailure := func(err error) bool { return false }
+
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(t, b.Execute(fail, notFailure), errBackend)
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
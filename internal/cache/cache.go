@@ -0,0 +1,365 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"l0/pkg/e"
+	"time"
+)
+
+// Item пара ключ-значение для пакетной записи в Backend
+type Item struct {
+	Key   string
+	Value []byte
+}
+
+// Backend хранилище сериализованных значений. Ключи передаются полностью
+// (с пространством имён). Отсутствующий ключ — e.ErrCacheMiss.
+type Backend interface {
+	// Name название слоя для статистики и логов
+	Name() string
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetMany возвращает значения в порядке keys, nil — для отсутствующих
+	GetMany(ctx context.Context, keys []string) ([][]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetMany(ctx context.Context, items []Item, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) (int64, error)
+	// TTL оставшийся срок жизни ключа, отрицательное значение — без срока
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// DeletePrefix удаляет все ключи с префиксом, не блокируя хранилище
+	DeletePrefix(ctx context.Context, prefix string) (int64, error)
+	// Tag привязывает ключи к тегам для последующей инвалидации
+	Tag(ctx context.Context, tags []string, keys []string, ttl time.Duration) error
+	// InvalidateTags удаляет все ключи, привязанные к тегам, и сами теги
+	InvalidateTags(ctx context.Context, tags ...string) (int64, error)
+}
+
+// Locker необязательная возможность Backend: короткая блокировка на ключ,
+// общая для всех экземпляров сервиса. TryLock возвращает токен владельца,
+// Unlock снимает блокировку, только если она всё ещё принадлежит токену:
+// блокировку, истёкшую и захваченную другим экземпляром, не снять.
+type Locker interface {
+	TryLock(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error)
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// NewLockToken случайный токен владельца блокировки
+func NewLockToken() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Options параметры типизированного кэша
+type Options struct {
+	// Namespace префикс ключей, обычно включает версию схемы: "orders:v1"
+	Namespace string
+	// Layer название слоя в статистике, по умолчанию Namespace
+	Layer             string
+	SchemaVersion     byte
+	Encoding          Encoding
+	CompressThreshold int
+	// TTL срок жизни записи по умолчанию
+	TTL time.Duration
+	// SoftTTL после истечения запись отдаётся как устаревшая (0 — не используется)
+	SoftTTL time.Duration
+}
+
+// SetOption переопределяет параметры записи
+type SetOption func(*setOptions)
+
+type setOptions struct {
+	ttl     time.Duration
+	softTTL time.Duration
+	tags    []string
+}
+
+// WithTTL задаёт срок жизни записи
+func WithTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) { o.ttl = ttl }
+}
+
+// WithSoftTTL задаёт мягкий срок жизни записи
+func WithSoftTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) { o.softTTL = ttl }
+}
+
+// WithTags привязывает запись к тегам для InvalidateTags
+func WithTags(tags ...string) SetOption {
+	return func(o *setOptions) { o.tags = append(o.tags, tags...) }
+}
+
+// Meta служебные данные прочитанной записи
+type Meta struct {
+	// Stale мягкий срок жизни истёк, запись стоит обновить
+	Stale bool
+}
+
+// Cache типизированный кэш поверх Backend
+type Cache[T any] struct {
+	backend Backend
+	codec   *envelopeCodec
+	opts    Options
+	stats   *Stats
+}
+
+func New[T any](backend Backend, opts Options) *Cache[T] {
+	if opts.Encoding == 0 {
+		opts.Encoding = EncodingJSON
+	}
+	if opts.Layer == "" {
+		opts.Layer = opts.Namespace
+	}
+	return &Cache[T]{
+		backend: backend,
+		codec:   newEnvelopeCodec(opts.SchemaVersion, opts.Encoding, opts.CompressThreshold),
+		opts:    opts,
+		stats:   NewStats(backend.Name() + ":" + opts.Layer),
+	}
+}
+
+func (c *Cache[T]) key(key string) string {
+	return c.opts.Namespace + ":" + key
+}
+
+func (c *Cache[T]) tagKey(tag string) string {
+	return c.opts.Namespace + ":tag:" + tag
+}
+
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	v, _, err := c.GetWithMeta(ctx, key)
+	return v, err
+}
+
+// GetWithMeta получает значение и сообщает, истёк ли его мягкий срок жизни
+func (c *Cache[T]) GetWithMeta(ctx context.Context, key string) (T, Meta, error) {
+	var zero T
+	data, err := c.backend.Get(ctx, c.key(key))
+	if err != nil {
+		c.countError(err)
+		return zero, Meta{}, err
+	}
+
+	v, meta, err := c.decode(ctx, c.key(key), data)
+	if err != nil {
+		return zero, Meta{}, err
+	}
+	return v, meta, nil
+}
+
+// GetMany возвращает найденные значения; отсутствующие ключи пропускаются
+func (c *Cache[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = c.key(k)
+	}
+
+	values, err := c.backend.GetMany(ctx, full)
+	if err != nil {
+		c.countError(err)
+		return nil, err
+	}
+
+	result := make(map[string]T, len(keys))
+	for i, data := range values {
+		if data == nil {
+			c.stats.Miss()
+			continue
+		}
+		v, _, err := c.decode(ctx, full[i], data)
+		if err != nil {
+			continue
+		}
+		result[keys[i]] = v
+	}
+	return result, nil
+}
+
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, opts ...SetOption) error {
+	return c.SetMany(ctx, map[string]T{key: value}, opts...)
+}
+
+func (c *Cache[T]) SetMany(ctx context.Context, items map[string]T, opts ...SetOption) error {
+	so := setOptions{ttl: c.opts.TTL, softTTL: c.opts.SoftTTL}
+	for _, opt := range opts {
+		opt(&so)
+	}
+
+	var softExpiry time.Time
+	if so.softTTL > 0 && (so.ttl <= 0 || so.softTTL < so.ttl) {
+		softExpiry = time.Now().Add(so.softTTL)
+	}
+
+	batch := make([]Item, 0, len(items))
+	keys := make([]string, 0, len(items))
+	for k, v := range items {
+		data, err := c.codec.encode(v, softExpiry)
+		if err != nil {
+			c.stats.Error()
+			return fmt.Errorf("cache encode %s: %w", k, err)
+		}
+		batch = append(batch, Item{Key: c.key(k), Value: data})
+		keys = append(keys, c.key(k))
+	}
+
+	var err error
+	if len(batch) == 1 {
+		err = c.backend.Set(ctx, batch[0].Key, batch[0].Value, so.ttl)
+	} else {
+		err = c.backend.SetMany(ctx, batch, so.ttl)
+	}
+	if err != nil {
+		c.countError(err)
+		return err
+	}
+
+	if len(so.tags) > 0 {
+		tags := make([]string, len(so.tags))
+		for i, t := range so.tags {
+			tags[i] = c.tagKey(t)
+		}
+		if err := c.backend.Tag(ctx, tags, keys, so.ttl); err != nil {
+			c.countError(err)
+			return fmt.Errorf("cache tag: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) (int64, error) {
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = c.key(k)
+	}
+	return c.backend.Delete(ctx, full...)
+}
+
+// InvalidateTags удаляет все записи, сохранённые с любым из тегов
+func (c *Cache[T]) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	full := make([]string, len(tags))
+	for i, t := range tags {
+		full[i] = c.tagKey(t)
+	}
+	return c.backend.InvalidateTags(ctx, full...)
+}
+
+// TryLock захватывает блокировку на ключ через Backend. Если Backend
+// блокировки не поддерживает, блокировка считается захваченной.
+func (c *Cache[T]) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	if l, ok := c.backend.(Locker); ok {
+		return l.TryLock(ctx, c.key(key), ttl)
+	}
+	return "", true, nil
+}
+
+// Unlock снимает блокировку, захваченную TryLock с токеном token
+func (c *Cache[T]) Unlock(ctx context.Context, key, token string) error {
+	if l, ok := c.backend.(Locker); ok {
+		return l.Unlock(ctx, c.key(key), token)
+	}
+	return nil
+}
+
+// CacheStats возвращает статистику обращений к кэшу
+func (c *Cache[T]) CacheStats() []LayerStats {
+	return []LayerStats{c.stats.Snapshot()}
+}
+
+// Inspect возвращает сохранённое значение, его TTL и формат без учёта в статистике
+func (c *Cache[T]) Inspect(ctx context.Context, key string) (Entry, error) {
+	full := c.key(key)
+	data, err := c.backend.Get(ctx, full)
+	if err != nil {
+		return Entry{}, err
+	}
+	ttl, err := c.backend.TTL(ctx, full)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Key:       full,
+		TTLMillis: ttlMillis(ttl),
+		Size:      len(data),
+	}
+	if meta, _, err := parseHeader(data); err == nil {
+		entry.SchemaVersion = int(meta.version)
+		entry.Encoding = meta.encoding.String()
+		entry.Compressed = meta.compressed
+		if !meta.softExpiry.IsZero() {
+			softExpiry := meta.softExpiry
+			entry.SoftExpiresAt = &softExpiry
+		}
+	}
+
+	var v T
+	if _, err := c.codec.decode(data, &v); err != nil {
+		entry.DecodeError = err.Error()
+	} else {
+		entry.Value = v
+	}
+	return entry, nil
+}
+
+// Evict удаляет запись, возвращает false если её не было
+func (c *Cache[T]) Evict(ctx context.Context, key string) (bool, error) {
+	n, err := c.Delete(ctx, key)
+	return n > 0, err
+}
+
+// Flush удаляет все записи пространства имён (всех версий схемы)
+func (c *Cache[T]) Flush(ctx context.Context) (int64, error) {
+	return c.backend.DeletePrefix(ctx, flushPrefix(c.opts.Namespace))
+}
+
+// decode распаковывает запись. Записи старого формата или другой версии
+// схемы удаляются и считаются промахом.
+func (c *Cache[T]) decode(ctx context.Context, fullKey string, data []byte) (T, Meta, error) {
+	var v T
+	meta, err := c.codec.decode(data, &v)
+	if err != nil {
+		if errors.Is(err, errBadEnvelope) || errors.Is(err, errVersionMismatch) {
+			c.stats.Miss()
+			_, _ = c.backend.Delete(ctx, fullKey)
+			return v, Meta{}, fmt.Errorf("%v: %w", err, e.ErrCacheMiss)
+		}
+		c.stats.Error()
+		return v, Meta{}, fmt.Errorf("could not unmarshal(cache): %v", err)
+	}
+
+	c.stats.Hit()
+	return v, Meta{Stale: !meta.softExpiry.IsZero() && time.Now().After(meta.softExpiry)}, nil
+}
+
+func (c *Cache[T]) countError(err error) {
+	switch {
+	case errors.Is(err, e.ErrCacheMiss):
+		c.stats.Miss()
+	case errors.Is(err, ErrCircuitOpen):
+		c.stats.Bypass()
+	default:
+		c.stats.Error()
+	}
+}
+
+// flushPrefix отбрасывает версию схемы из пространства имён, чтобы
+// очистка затрагивала записи всех версий: "orders:v1" -> "orders:"
+func flushPrefix(namespace string) string {
+	for i := len(namespace) - 1; i >= 0; i-- {
+		if namespace[i] == ':' {
+			return namespace[:i+1]
+		}
+	}
+	return namespace + ":"
+}
+
+// ttlMillis переводит TTL в миллисекунды, -1 означает запись без срока жизни
+func ttlMillis(ttl time.Duration) int64 {
+	if ttl < 0 {
+		return -1
+	}
+	return ttl.Milliseconds()
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"l0/internal/domain"
+	"l0/pkg/e"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listPage struct {
+	IDs   []int `json:"ids"`
+	Total int   `json:"total"`
+}
+
+func TestCache_GetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewOrderCache(NewMemory(), Options{Encoding: EncodingMsgpack, TTL: time.Minute})
+
+	_, err := c.Get(ctx, OrderKey(1))
+	assert.ErrorIs(t, err, e.ErrCacheMiss)
+
+	require.NoError(t, c.Set(ctx, OrderKey(1), testOrder()))
+	got, err := c.Get(ctx, OrderKey(1))
+	require.NoError(t, err)
+	assert.Equal(t, testOrder(), got)
+
+	n, err := c.Delete(ctx, OrderKey(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	stats := c.CacheStats()[0]
+	assert.Equal(t, "memory:"+OrderNamespace, stats.Layer)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+}
+
+func TestCache_Many(t *testing.T) {
+	ctx := context.Background()
+	c := New[listPage](NewMemory(), Options{Namespace: "pages:v1", SchemaVersion: 1})
+
+	require.NoError(t, c.SetMany(ctx, map[string]listPage{
+		"1": {IDs: []int{1, 2}, Total: 4},
+		"2": {IDs: []int{3, 4}, Total: 4},
+	}))
+
+	got, err := c.GetMany(ctx, []string{"1", "2", "3"})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, []int{3, 4}, got["2"].IDs)
+}
+
+func TestCache_InvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	c := NewOrderCache(NewMemory(), Options{})
+
+	require.NoError(t, c.Set(ctx, "1", testOrder(), WithTags("customer:test")))
+	require.NoError(t, c.Set(ctx, "2", testOrder(), WithTags("customer:test", "entry:WBIL")))
+	require.NoError(t, c.Set(ctx, "3", testOrder(), WithTags("entry:WBIL")))
+
+	n, err := c.InvalidateTags(ctx, "customer:test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	got, err := c.GetMany(ctx, []string{"1", "2", "3"})
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Contains(t, got, "3")
+}
+
+func TestCache_SoftTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewOrderCache(NewMemory(), Options{TTL: time.Hour, SoftTTL: time.Millisecond})
+
+	require.NoError(t, c.Set(ctx, "1", testOrder()))
+	time.Sleep(5 * time.Millisecond)
+
+	_, meta, err := c.GetWithMeta(ctx, "1")
+	require.NoError(t, err)
+	assert.True(t, meta.Stale)
+
+	require.NoError(t, c.Set(ctx, "1", testOrder(), WithSoftTTL(time.Hour)))
+	_, meta, err = c.GetWithMeta(ctx, "1")
+	require.NoError(t, err)
+	assert.False(t, meta.Stale)
+}
+
+func TestCache_OldSchemaIsMiss(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory()
+	old := New[domain.Order](backend, Options{Namespace: "orders:v1", SchemaVersion: 1})
+	current := New[domain.Order](backend, Options{Namespace: "orders:v1", SchemaVersion: 2})
+
+	require.NoError(t, old.Set(ctx, "1", testOrder()))
+	_, err := current.Get(ctx, "1")
+	assert.ErrorIs(t, err, e.ErrCacheMiss)
+
+	_, err = backend.Get(ctx, "orders:v1:1")
+	assert.ErrorIs(t, err, e.ErrCacheMiss, "stale entry is dropped")
+}
+
+func TestCache_FlushAllVersions(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory()
+	require.NoError(t, backend.Set(ctx, "orders:v0:1", []byte("legacy"), 0))
+	require.NoError(t, backend.Set(ctx, "other:1", []byte("keep"), 0))
+
+	c := NewOrderCache(backend, Options{})
+	require.NoError(t, c.Set(ctx, "1", testOrder()))
+
+	n, err := c.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	_, err = backend.Get(ctx, "other:1")
+	assert.NoError(t, err)
+}
+
+func TestCache_Rendered(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory()
+	c := NewOrderResponseCache(backend, Options{CompressThreshold: 1})
+
+	body := []byte(`{"order":{"order_uid":"b563feb7b2b84b6test"}}`)
+	require.NoError(t, c.Set(ctx, OrderResponseKey(1), NewRendered(body)))
+
+	got, err := c.Get(ctx, OrderResponseKey(1))
+	require.NoError(t, err)
+	assert.Equal(t, body, got.Body)
+	assert.Equal(t, NewRendered(body).ETag, got.ETag)
+
+	// ответ удаляется вместе с заказами при очистке пространства имён
+	n, err := NewOrderCache(backend, Options{}).Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestCache_UnlockChecksOwner(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemory()
+	now := time.Now()
+	mem.now = func() time.Time { return now }
+	c := NewOrderCache(mem, Options{TTL: time.Minute})
+
+	first, ok, err := c.TryLock(ctx, "1", time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, _ = c.TryLock(ctx, "1", time.Second)
+	assert.False(t, ok)
+
+	// блокировка истекла и перешла к другому владельцу
+	now = now.Add(2 * time.Second)
+	second, ok, _ := c.TryLock(ctx, "1", time.Second)
+	require.True(t, ok)
+	require.NoError(t, c.Unlock(ctx, "1", first))
+	_, ok, _ = c.TryLock(ctx, "1", time.Second)
+	assert.False(t, ok, "stale owner must not release the lock")
+
+	require.NoError(t, c.Unlock(ctx, "1", second))
+	_, ok, _ = c.TryLock(ctx, "1", time.Second)
+	assert.True(t, ok)
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Формат записи в кэше:
+//
+//	[0] magic        — признак конверта, отличает его от старых "голых" JSON-записей
+//	[1] version      — версия схемы сохранённой структуры
+//	[2] encoding     — способ сериализации полезной нагрузки
+//	[3] flags        — флаги (сжатие и т.п.)
+//	[4:12]           — мягкий срок жизни, unix ms big-endian (только при flagSoftExpiry)
+//	[...] payload
+const (
+	envelopeMagic      byte = 0xCE
+	envelopeHeaderSize      = 4
+	softExpirySize          = 8
+
+	flagGzip       byte = 1 << 0
+	flagSoftExpiry byte = 1 << 1
+)
+
+// Encoding способ сериализации значения внутри конверта
+type Encoding byte
+
+const (
+	EncodingJSON    Encoding = 1
+	EncodingMsgpack Encoding = 2
+	// EncodingRaw значение само сериализует себя через encoding.BinaryMarshaler
+	EncodingRaw Encoding = 3
+)
+
+// envelopeMeta служебные данные конверта
+type envelopeMeta struct {
+	version    byte
+	encoding   Encoding
+	compressed bool
+	softExpiry time.Time
+}
+
+var (
+	errBadEnvelope     = errors.New("invalid cache envelope")
+	errVersionMismatch = errors.New("cache entry schema version mismatch")
+)
+
+// ParseEncoding преобразует название из конфигурации в Encoding
+func ParseEncoding(s string) (Encoding, error) {
+	switch s {
+	case "", "json":
+		return EncodingJSON, nil
+	case "msgpack":
+		return EncodingMsgpack, nil
+	default:
+		return 0, fmt.Errorf("unknown cache encoding %q", s)
+	}
+}
+
+func (enc Encoding) String() string {
+	switch enc {
+	case EncodingJSON:
+		return "json"
+	case EncodingMsgpack:
+		return "msgpack"
+	case EncodingRaw:
+		return "raw"
+	default:
+		return fmt.Sprintf("encoding(%d)", byte(enc))
+	}
+}
+
+// envelopeCodec упаковывает значения в версионированный конверт
+type envelopeCodec struct {
+	version           byte
+	encoding          Encoding
+	compressThreshold int
+	msgpack           *codec.MsgpackHandle
+}
+
+func newEnvelopeCodec(version byte, encoding Encoding, compressThreshold int) *envelopeCodec {
+	mh := &codec.MsgpackHandle{}
+	mh.WriteExt = true
+	// используем json-теги структур, чтобы имена полей совпадали с JSON-представлением
+	mh.TypeInfos = codec.NewTypeInfos([]string{"json"})
+
+	return &envelopeCodec{
+		version:           version,
+		encoding:          encoding,
+		compressThreshold: compressThreshold,
+		msgpack:           mh,
+	}
+}
+
+// encode упаковывает значение; ненулевой softExpiry сохраняется в заголовке
+func (c *envelopeCodec) encode(value interface{}, softExpiry time.Time) ([]byte, error) {
+	var payload []byte
+	switch c.encoding {
+	case EncodingJSON:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("json marshal: %w", err)
+		}
+		payload = b
+	case EncodingMsgpack:
+		if err := codec.NewEncoderBytes(&payload, c.msgpack).Encode(value); err != nil {
+			return nil, fmt.Errorf("msgpack marshal: %w", err)
+		}
+	case EncodingRaw:
+		m, ok := value.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("raw encoding requires encoding.BinaryMarshaler, got %T", value)
+		}
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("raw marshal: %w", err)
+		}
+		payload = b
+	default:
+		return nil, fmt.Errorf("unsupported encoding %s", c.encoding)
+	}
+
+	var flags byte
+	if c.compressThreshold > 0 && len(payload) >= c.compressThreshold {
+		compressed, err := gzipBytes(payload)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		payload = compressed
+		flags |= flagGzip
+	}
+
+	if !softExpiry.IsZero() {
+		flags |= flagSoftExpiry
+	}
+
+	out := make([]byte, 0, envelopeHeaderSize+softExpirySize+len(payload))
+	out = append(out, envelopeMagic, c.version, byte(c.encoding), flags)
+	if !softExpiry.IsZero() {
+		out = binary.BigEndian.AppendUint64(out, uint64(softExpiry.UnixMilli()))
+	}
+	return append(out, payload...), nil
+}
+
+// parseHeader разбирает заголовок конверта и возвращает полезную нагрузку
+func parseHeader(data []byte) (envelopeMeta, []byte, error) {
+	if len(data) < envelopeHeaderSize || data[0] != envelopeMagic {
+		return envelopeMeta{}, nil, errBadEnvelope
+	}
+	meta := envelopeMeta{
+		version:    data[1],
+		encoding:   Encoding(data[2]),
+		compressed: data[3]&flagGzip != 0,
+	}
+	payload := data[envelopeHeaderSize:]
+	if data[3]&flagSoftExpiry != 0 {
+		if len(payload) < softExpirySize {
+			return envelopeMeta{}, nil, errBadEnvelope
+		}
+		meta.softExpiry = time.UnixMilli(int64(binary.BigEndian.Uint64(payload)))
+		payload = payload[softExpirySize:]
+	}
+	return meta, payload, nil
+}
+
+// decode распаковывает конверт в dest. Записи другой версии схемы
+// возвращают errVersionMismatch и должны считаться промахом кэша.
+func (c *envelopeCodec) decode(data []byte, dest interface{}) (envelopeMeta, error) {
+	meta, payload, err := parseHeader(data)
+	if err != nil {
+		return meta, err
+	}
+	if meta.version != c.version {
+		return meta, errVersionMismatch
+	}
+
+	if meta.compressed {
+		b, err := gunzipBytes(payload)
+		if err != nil {
+			return meta, fmt.Errorf("gunzip: %w", err)
+		}
+		payload = b
+	}
+
+	switch meta.encoding {
+	case EncodingJSON:
+		err = json.Unmarshal(payload, dest)
+	case EncodingMsgpack:
+		err = codec.NewDecoderBytes(payload, c.msgpack).Decode(dest)
+	case EncodingRaw:
+		u, ok := dest.(encoding.BinaryUnmarshaler)
+		if !ok {
+			return meta, fmt.Errorf("raw encoding requires encoding.BinaryUnmarshaler, got %T", dest)
+		}
+		err = u.UnmarshalBinary(payload)
+	default:
+		err = fmt.Errorf("%w: unknown encoding %d", errBadEnvelope, meta.encoding)
+	}
+	return meta, err
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
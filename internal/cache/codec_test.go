@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"bytes"
+	"l0/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder() domain.Order {
+	return domain.Order{
+		OrderUID: "b563feb7b2b84b6test",
+		Entry:    "WBIL",
+		Payment:  domain.Payment{Transaction: "b563feb7b2b84b6test", Currency: "USD", Amount: 1817},
+		Items: []domain.Items{
+			{ChrtID: 9934930, Price: 453, Name: "Mascaras", TotalPrice: 317, Brand: "Vivienne Sabo"},
+		},
+		Delivery: domain.Delivery{Name: "Test Testov", City: "Kiryat Mozkin"},
+	}
+}
+
+func TestEnvelopeCodec_RoundTrip(t *testing.T) {
+	for _, enc := range []Encoding{EncodingJSON, EncodingMsgpack} {
+		for _, threshold := range []int{0, 1} {
+			c := newEnvelopeCodec(domain.OrderSchemaVersion, enc, threshold)
+
+			data, err := c.encode(testOrder(), time.Time{})
+			require.NoError(t, err)
+			assert.Equal(t, byte(enc), data[2])
+			assert.Equal(t, threshold > 0, data[3]&flagGzip != 0)
+
+			var got domain.Order
+			_, err = c.decode(data, &got)
+			require.NoError(t, err)
+			assert.Equal(t, testOrder(), got, "encoding %s, threshold %d", enc, threshold)
+		}
+	}
+}
+
+func TestEnvelopeCodec_MsgpackIsCompact(t *testing.T) {
+	jsonData, err := newEnvelopeCodec(1, EncodingJSON, 0).encode(testOrder(), time.Time{})
+	require.NoError(t, err)
+	msgpackData, err := newEnvelopeCodec(1, EncodingMsgpack, 0).encode(testOrder(), time.Time{})
+	require.NoError(t, err)
+
+	assert.Less(t, len(msgpackData), len(jsonData))
+}
+
+func TestEnvelopeCodec_StaleEntries(t *testing.T) {
+	c := newEnvelopeCodec(2, EncodingJSON, 0)
+	var dest domain.Order
+
+	// запись в старом формате без конверта
+	_, err := c.decode([]byte(`{"order_uid":"x"}`), &dest)
+	assert.ErrorIs(t, err, errBadEnvelope)
+
+	old, err := newEnvelopeCodec(1, EncodingJSON, 0).encode(testOrder(), time.Time{})
+	require.NoError(t, err)
+	_, err = c.decode(old, &dest)
+	assert.ErrorIs(t, err, errVersionMismatch)
+
+	_, err = c.decode(bytes.Repeat([]byte{envelopeMagic}, 2), &dest)
+	assert.ErrorIs(t, err, errBadEnvelope)
+}
+
+func TestEnvelopeCodec_SoftExpiry(t *testing.T) {
+	c := newEnvelopeCodec(1, EncodingMsgpack, 1)
+	softExpiry := time.UnixMilli(1_750_000_000_123)
+
+	data, err := c.encode(testOrder(), softExpiry)
+	require.NoError(t, err)
+
+	var got domain.Order
+	meta, err := c.decode(data, &got)
+	require.NoError(t, err)
+	assert.True(t, meta.softExpiry.Equal(softExpiry))
+	assert.True(t, meta.compressed)
+	assert.Equal(t, testOrder(), got)
+}
+
+func TestParseEncoding(t *testing.T) {
+	enc, err := ParseEncoding("")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingJSON, enc)
+
+	enc, err = ParseEncoding("msgpack")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingMsgpack, enc)
+
+	_, err = ParseEncoding("protobuf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+package cache
+
+import "time"
+
+// Entry описание записи в кэше для администрирования
+type Entry struct {
+	Key           string     `json:"key"`
+	TTLMillis     int64      `json:"ttl_ms"`
+	Size          int        `json:"size"`
+	SchemaVersion int        `json:"schema_version"`
+	Encoding      string     `json:"encoding"`
+	Compressed    bool       `json:"compressed"`
+	SoftExpiresAt *time.Time `json:"soft_expires_at,omitempty"`
+	Value         any        `json:"value,omitempty"`
+	DecodeError   string     `json:"decode_error,omitempty"`
+}
@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"l0/pkg/e"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+// Memory Backend в памяти процесса. Просроченные записи удаляются при
+// обращении и периодически в Sweep.
+type Memory struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	tags  map[string]map[string]struct{}
+	locks map[string]memoryLock
+	now   func() time.Time
+}
+
+type memoryLock struct {
+	token string
+	until time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		items: make(map[string]memoryItem),
+		tags:  make(map[string]map[string]struct{}),
+		locks: make(map[string]memoryLock),
+		now:   time.Now,
+	}
+}
+
+func (m *Memory) Name() string { return "memory" }
+
+func (m *Memory) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
+
+// lookup возвращает живую запись, удаляя просроченную; вызывается под m.mu
+func (m *Memory) lookup(key string) ([]byte, bool) {
+	item, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	if item.expired(m.now()) {
+		delete(m.items, key)
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.lookup(key)
+	if !ok {
+		return nil, e.ErrCacheMiss
+	}
+	return v, nil
+}
+
+func (m *Memory) GetMany(_ context.Context, keys []string) ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([][]byte, len(keys))
+	for i, k := range keys {
+		out[i], _ = m.lookup(k)
+	}
+	return out, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items[key] = memoryItem{value: value, expiresAt: m.expiry(ttl)}
+	return nil
+}
+
+func (m *Memory) SetMany(_ context.Context, items []Item, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.expiry(ttl)
+	for _, it := range items {
+		m.items[it.Key] = memoryItem{value: it.Value, expiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, k := range keys {
+		if _, ok := m.lookup(k); ok {
+			delete(m.items, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.lookup(key); !ok {
+		return 0, e.ErrCacheMiss
+	}
+	item := m.items[key]
+	if item.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return item.expiresAt.Sub(m.now()), nil
+}
+
+func (m *Memory) DeletePrefix(_ context.Context, prefix string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for k := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.items, k)
+			n++
+		}
+	}
+	for t := range m.tags {
+		if strings.HasPrefix(t, prefix) {
+			delete(m.tags, t)
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) Tag(_ context.Context, tags []string, keys []string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tags {
+		set, ok := m.tags[t]
+		if !ok {
+			set = make(map[string]struct{})
+			m.tags[t] = set
+		}
+		for _, k := range keys {
+			set[k] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (m *Memory) InvalidateTags(_ context.Context, tags ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, t := range tags {
+		for k := range m.tags[t] {
+			if _, ok := m.lookup(k); ok {
+				delete(m.items, k)
+				n++
+			}
+		}
+		delete(m.tags, t)
+	}
+	return n, nil
+}
+
+func (m *Memory) TryLock(_ context.Context, key string, ttl time.Duration) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if l, ok := m.locks[key]; ok && m.now().Before(l.until) {
+		return "", false, nil
+	}
+	token := NewLockToken()
+	m.locks[key] = memoryLock{token: token, until: m.now().Add(ttl)}
+	return token, true, nil
+}
+
+func (m *Memory) Unlock(_ context.Context, key, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.locks[key].token == token {
+		delete(m.locks, key)
+	}
+	return nil
+}
+
+// Sweep удаляет просроченные записи и блокировки
+func (m *Memory) Sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for k, item := range m.items {
+		if item.expired(now) {
+			delete(m.items, k)
+		}
+	}
+	for k, l := range m.locks {
+		if !now.Before(l.until) {
+			delete(m.locks, k)
+		}
+	}
+	for t, set := range m.tags {
+		for k := range set {
+			if _, ok := m.items[k]; !ok {
+				delete(set, k)
+			}
+		}
+		if len(set) == 0 {
+			delete(m.tags, t)
+		}
+	}
+}
+
+// RunSweeper периодически вызывает Sweep до отмены ctx
+func (m *Memory) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep()
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"fmt"
+	"l0/internal/domain"
+	"strconv"
+)
+
+// OrderNamespace пространство имён заказов с учётом версии схемы
+var OrderNamespace = fmt.Sprintf("orders:v%d", domain.OrderSchemaVersion)
+
+// OrderKey ключ заказа внутри OrderNamespace
+func OrderKey(id int) string {
+	return strconv.Itoa(id)
+}
+
+// OrderResponseKey ключ готового ответа API по заказу. Лежит в том же
+// пространстве имён, поэтому удаляется вместе с заказом при очистке.
+func OrderResponseKey(id int) string {
+	return "resp:" + strconv.Itoa(id)
+}
+
+// NewOrderCache создаёт кэш заказов поверх backend
+func NewOrderCache(backend Backend, opts Options) *Cache[domain.Order] {
+	opts.Namespace = OrderNamespace
+	opts.SchemaVersion = domain.OrderSchemaVersion
+	return New[domain.Order](backend, opts)
+}
+
+// NewOrderResponseCache создаёт кэш готовых ответов API по заказам
+func NewOrderResponseCache(backend Backend, opts Options) *Cache[Rendered] {
+	opts.Namespace = OrderNamespace
+	opts.Layer = OrderNamespace + ":resp"
+	opts.SchemaVersion = domain.OrderSchemaVersion
+	opts.Encoding = EncodingRaw
+	return New[Rendered](backend, opts)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// Rendered заранее сериализованное тело ответа API вместе с ETag,
+// вычисленным при записи
+type Rendered struct {
+	ETag string
+	Body []byte
+}
+
+// NewRendered вычисляет сильный ETag по содержимому body
+func NewRendered(body []byte) Rendered {
+	sum := sha256.Sum256(body)
+	return Rendered{
+		ETag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Body: body,
+	}
+}
+
+// MarshalBinary формат: uint16 длина ETag, ETag, тело ответа
+func (r Rendered) MarshalBinary() ([]byte, error) {
+	if len(r.ETag) > 0xFFFF {
+		return nil, errors.New("etag is too long")
+	}
+	out := make([]byte, 0, 2+len(r.ETag)+len(r.Body))
+	out = binary.BigEndian.AppendUint16(out, uint16(len(r.ETag)))
+	out = append(out, r.ETag...)
+	return append(out, r.Body...), nil
+}
+
+func (r *Rendered) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("rendered: short buffer")
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return errors.New("rendered: truncated etag")
+	}
+	r.ETag = string(data[2 : 2+n])
+	r.Body = data[2+n:]
+	return nil
+}
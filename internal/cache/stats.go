@@ -0,0 +1,47 @@
+package cache
+
+import "sync/atomic"
+
+// Stats счётчики обращений к одному слою кэша
+type Stats struct {
+	layer  string
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+	// bypassed обращения, пропущенные из-за разомкнутого circuit breaker
+	bypassed atomic.Int64
+}
+
+// LayerStats снимок статистики слоя кэша
+type LayerStats struct {
+	Layer    string  `json:"layer"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"`
+	Bypassed int64   `json:"bypassed"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+func NewStats(layer string) *Stats {
+	return &Stats{layer: layer}
+}
+
+func (s *Stats) Hit()    { s.hits.Add(1) }
+func (s *Stats) Miss()   { s.misses.Add(1) }
+func (s *Stats) Error()  { s.errors.Add(1) }
+func (s *Stats) Bypass() { s.bypassed.Add(1) }
+
+// Snapshot возвращает текущие значения счётчиков и долю попаданий
+func (s *Stats) Snapshot() LayerStats {
+	snap := LayerStats{
+		Layer:    s.layer,
+		Hits:     s.hits.Load(),
+		Misses:   s.misses.Load(),
+		Errors:   s.errors.Load(),
+		Bypassed: s.bypassed.Load(),
+	}
+	if total := snap.Hits + snap.Misses; total > 0 {
+		snap.HitRatio = float64(snap.Hits) / float64(total)
+	}
+	return snap
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/joho/godotenv"
 )
 
@@ -15,18 +21,64 @@ type Config struct {
 	Redis    RedisConfig
 	Postgres PostgresConfig
 	Kafka    KafkaConfig
+	Rules    RulesConfig
+}
+
+// RulesConfig правила проверки заказов по Entry
+type RulesConfig struct {
+	// File YAML-файл правил; пустой — правил нет
+	File string `env:"RULES_FILE"`
+	// ReloadInterval период проверки файла на изменения, 0 — без перезагрузки
+	ReloadInterval time.Duration `env:"RULES_RELOAD_INTERVAL"`
 }
 
 type HTTPConfig struct {
-	Port string `env:"HTTP_PORT"`
+	Port       string `env:"HTTP_PORT"`
+	AdminToken string `env:"ADMIN_TOKEN"`
 }
 
 type RedisConfig struct {
 	Addrs    []string `env:"REDIS_ADDRS"`
+	Username string   `env:"REDIS_USERNAME"`
 	Password string   `env:"REDIS_PASSWORD"`
 	DBRedis  int      `env:"REDIS_DB"`
+
+	SentinelMaster   string `env:"REDIS_SENTINEL_MASTER"`
+	SentinelPassword string `env:"REDIS_SENTINEL_PASSWORD"`
+	ClusterMode      bool   `env:"REDIS_CLUSTER"`
+
+	TLSEnabled            bool   `env:"REDIS_TLS"`
+	TLSCAFile             string `env:"REDIS_TLS_CA_FILE"`
+	TLSCertFile           string `env:"REDIS_TLS_CERT_FILE"`
+	TLSKeyFile            string `env:"REDIS_TLS_KEY_FILE"`
+	TLSInsecureSkipVerify bool   `env:"REDIS_TLS_INSECURE_SKIP_VERIFY"`
+
+	PoolSize        int           `env:"REDIS_POOL_SIZE"`
+	MinIdleConns    int           `env:"REDIS_MIN_IDLE_CONNS"`
+	DialTimeout     time.Duration `env:"REDIS_DIAL_TIMEOUT"`
+	ReadTimeout     time.Duration `env:"REDIS_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `env:"REDIS_WRITE_TIMEOUT"`
+	MaxRetries      int           `env:"REDIS_MAX_RETRIES"`
+	MinRetryBackoff time.Duration `env:"REDIS_MIN_RETRY_BACKOFF"`
+	MaxRetryBackoff time.Duration `env:"REDIS_MAX_RETRY_BACKOFF"`
+
+	// CacheBackend хранилище кэша: redis (по умолчанию) или memory
+	CacheBackend      string `env:"CACHE_BACKEND"`
+	CacheEncoding     string `env:"REDIS_CACHE_ENCODING"`
+	CompressThreshold int    `env:"REDIS_COMPRESS_THRESHOLD"`
+	// CacheSoftTTL после истечения запись ещё отдаётся, но обновляется в фоне;
+	// CacheHardTTL — срок жизни ключа в Redis
+	CacheSoftTTL time.Duration `env:"REDIS_CACHE_SOFT_TTL"`
+	CacheHardTTL time.Duration `env:"REDIS_CACHE_HARD_TTL"`
+
+	BreakerFailures       int           `env:"REDIS_BREAKER_FAILURES"`
+	BreakerOpenTimeout    time.Duration `env:"REDIS_BREAKER_OPEN_TIMEOUT"`
+	BreakerHalfOpenProbes int           `env:"REDIS_BREAKER_HALF_OPEN_PROBES"`
 }
 
+// DefaultCacheHardTTL срок жизни заказа в кэше, если REDIS_CACHE_HARD_TTL не задан
+const DefaultCacheHardTTL = 5 * time.Minute
+
 type PostgresConfig struct {
 	Host     string `env:"POSTGRES_HOST"`
 	Port     string `env:"POSTGRES_PORT"`
@@ -42,8 +94,111 @@ type KafkaConfig struct {
 	InitialBackoff time.Duration `env:"KAFKA_INITIAL_BACKOFF"`
 	MaxRetries     int           `env:"KAFKA_MAX_RETRIES"`
 	ConsumerGroup  string        `env:"KAFKA_CONSUMER_GROUP"`
+	// InitialOffset откуда читать, если у группы нет сохранённого смещения:
+	// oldest (по умолчанию) или newest
+	InitialOffset string `env:"KAFKA_INITIAL_OFFSET"`
+	// RebalanceStrategy распределение партиций в группе: range, roundrobin или sticky
+	RebalanceStrategy string `env:"KAFKA_REBALANCE_STRATEGY"`
+	// CommitInterval период фиксации отмеченных смещений
+	CommitInterval time.Duration `env:"KAFKA_COMMIT_INTERVAL"`
+	// DLQTopic топик для сообщений, которые не удалось обработать,
+	// по умолчанию <KAFKA_TOPIC>.dlq
+	DLQTopic string `env:"KAFKA_DLQ_TOPIC"`
+	// BatchSize сколько сообщений партиции сохранять одной транзакцией,
+	// 0 или 1 — по одному
+	BatchSize int `env:"KAFKA_BATCH_SIZE"`
+	// BatchLinger сколько ждать наполнения пакета, прежде чем сохранить неполный
+	BatchLinger time.Duration `env:"KAFKA_BATCH_LINGER"`
+	// Workers сколько сообщений партиции обрабатывать параллельно,
+	// 0 или 1 — последовательно. Несовместимо с KAFKA_BATCH_SIZE.
+	Workers int `env:"KAFKA_WORKERS"`
+	// OrderingKey по чему сохраняется порядок при параллельной обработке:
+	// key (ключ сообщения, по умолчанию), order_uid или customer_id
+	OrderingKey string `env:"KAFKA_ORDERING_KEY"`
+	// RetryDelays задержки уровней топиков повторов (<KAFKA_TOPIC>.retry.<delay>),
+	// например 10s,1m,10m. Пусто — повторы на месте с KAFKA_INITIAL_BACKOFF.
+	RetryDelays []time.Duration `env:"KAFKA_RETRY_DELAYS"`
+	// LagThreshold отставание партиции в сообщениях, после которого проверка
+	// состояния сообщает degraded; 0 — не проверять
+	LagThreshold int `env:"KAFKA_LAG_THRESHOLD"`
+	// FatalErrorPolicy что делать, если группа не смогла провести сессию:
+	// restart (по умолчанию) — перезапустить консьюмер с задержкой, exit — завершить процесс
+	FatalErrorPolicy string `env:"KAFKA_FATAL_ERROR_POLICY"`
+	// MessageErrorPolicy что делать, если сообщение не удалось ни сохранить, ни
+	// отправить в DLQ: restart (по умолчанию) — перечитать его в новой сессии,
+	// exit — завершить процесс
+	MessageErrorPolicy string `env:"KAFKA_MESSAGE_ERROR_POLICY"`
+	// MaxBackoff предельная задержка между перезапусками консьюмера
+	MaxBackoff time.Duration `env:"KAFKA_MAX_BACKOFF"`
+	// ExactlyOnce обработанные сообщения отмечаются в Postgres в транзакции с
+	// их данными, а позиции партиций восстанавливаются из неё при назначении
+	// партиций; по умолчанию включено
+	ExactlyOnce bool `env:"KAFKA_EXACTLY_ONCE"`
+
+	// TopicHandlers дополнительные топики и их обработчики:
+	// payment (подтверждения оплаты) или delivery_status (статусы доставки),
+	// например payments=payment,deliveries=delivery_status. KAFKA_TOPIC
+	// всегда обрабатывается как заказы.
+	TopicHandlers map[string]string `env:"KAFKA_TOPIC_HANDLERS"`
+
+	// EventsTopic топик исходящих событий о заказах, пусто — не публиковать
+	EventsTopic string `env:"KAFKA_EVENTS_TOPIC"`
+	// EventsAcks подтверждение записи событий: all (по умолчанию), leader или none
+	EventsAcks string `env:"KAFKA_EVENTS_ACKS"`
+	// EventsIdempotent идемпотентный продюсер событий, по умолчанию включён
+	EventsIdempotent bool `env:"KAFKA_EVENTS_IDEMPOTENT"`
+
+	// SchemaRegistryURL адрес Schema Registry для сообщений Avro и Protobuf
+	SchemaRegistryURL string `env:"SCHEMA_REGISTRY_URL"`
+	// SchemaRegistryDir каталог локального реестра схем вместо SchemaRegistryURL
+	SchemaRegistryDir string `env:"SCHEMA_REGISTRY_DIR"`
+
+	KafkaClientConfig
 }
 
+// KafkaClientConfig настройки подключения к брокерам, общие для консьюмера и
+// всех продюсеров
+type KafkaClientConfig struct {
+	// ClientID имя клиента в логах и квотах брокера, по умолчанию sarama
+	ClientID string `env:"KAFKA_CLIENT_ID"`
+	// Version версия протокола Kafka, например 3.6.0; пусто — версия по умолчанию sarama
+	Version string `env:"KAFKA_VERSION"`
+
+	TLSEnabled  bool   `env:"KAFKA_TLS"`
+	TLSCAFile   string `env:"KAFKA_TLS_CA_FILE"`
+	TLSCertFile string `env:"KAFKA_TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"KAFKA_TLS_KEY_FILE"`
+	// TLSInsecureSkipVerify не проверять сертификат брокера, только для тестовых стендов
+	TLSInsecureSkipVerify bool `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY"`
+
+	// SASLMechanism PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512; пусто — без SASL
+	SASLMechanism string `env:"KAFKA_SASL_MECHANISM"`
+	SASLUsername  string `env:"KAFKA_SASL_USERNAME"`
+	SASLPassword  string `env:"KAFKA_SASL_PASSWORD"`
+
+	DialTimeout time.Duration `env:"KAFKA_DIAL_TIMEOUT"`
+	// FetchMinBytes, FetchDefaultBytes и FetchMaxBytes размеры запросов
+	// чтения; 0 — значения sarama
+	FetchMinBytes     int `env:"KAFKA_FETCH_MIN_BYTES"`
+	FetchDefaultBytes int `env:"KAFKA_FETCH_DEFAULT_BYTES"`
+	FetchMaxBytes     int `env:"KAFKA_FETCH_MAX_BYTES"`
+	// SessionTimeout через сколько без heartbeat брокер исключает консьюмер из группы
+	SessionTimeout    time.Duration `env:"KAFKA_SESSION_TIMEOUT"`
+	HeartbeatInterval time.Duration `env:"KAFKA_HEARTBEAT_INTERVAL"`
+}
+
+// DefaultMaxBackoff предельная задержка перезапуска, если KAFKA_MAX_BACKOFF не задан
+const DefaultMaxBackoff = time.Minute
+
+// DefaultLagThreshold порог отставания, если KAFKA_LAG_THRESHOLD не задан
+const DefaultLagThreshold = 10000
+
+// DefaultRulesReloadInterval период проверки файла правил, если RULES_RELOAD_INTERVAL не задан
+const DefaultRulesReloadInterval = 5 * time.Second
+
+// DefaultBatchLinger ожидание наполнения пакета, если KAFKA_BATCH_LINGER не задан
+const DefaultBatchLinger = 100 * time.Millisecond
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// .env может отсутствовать в некоторых окружениях, не обязательно ошибку делать
@@ -54,17 +209,47 @@ func LoadConfig() (*Config, error) {
 	cfg.Env = os.Getenv("ENV")
 
 	cfg.Http.Port = os.Getenv("HTTP_PORT")
+	cfg.Http.AdminToken = os.Getenv("ADMIN_TOKEN")
 
 	redisAddrs := os.Getenv("REDIS_ADDRS")
 	if redisAddrs != "" {
 		cfg.Redis.Addrs = splitAndTrim(redisAddrs, ",")
 	}
+	cfg.Redis.Username = os.Getenv("REDIS_USERNAME")
 	cfg.Redis.Password = os.Getenv("REDIS_PASSWORD")
-	dbRedisStr := os.Getenv("REDIS_DB")
-	if dbRedisStr != "" {
-		if dbIndex, err := strconv.Atoi(dbRedisStr); err == nil {
-			cfg.Redis.DBRedis = dbIndex
-		}
+	cfg.Redis.SentinelMaster = os.Getenv("REDIS_SENTINEL_MASTER")
+	cfg.Redis.SentinelPassword = os.Getenv("REDIS_SENTINEL_PASSWORD")
+	cfg.Redis.TLSCAFile = os.Getenv("REDIS_TLS_CA_FILE")
+	cfg.Redis.TLSCertFile = os.Getenv("REDIS_TLS_CERT_FILE")
+	cfg.Redis.TLSKeyFile = os.Getenv("REDIS_TLS_KEY_FILE")
+
+	var errs []error
+	errs = append(errs,
+		envInt("REDIS_DB", &cfg.Redis.DBRedis),
+		envBool("REDIS_CLUSTER", &cfg.Redis.ClusterMode),
+		envBool("REDIS_TLS", &cfg.Redis.TLSEnabled),
+		envBool("REDIS_TLS_INSECURE_SKIP_VERIFY", &cfg.Redis.TLSInsecureSkipVerify),
+		envInt("REDIS_POOL_SIZE", &cfg.Redis.PoolSize),
+		envInt("REDIS_MIN_IDLE_CONNS", &cfg.Redis.MinIdleConns),
+		envDuration("REDIS_DIAL_TIMEOUT", &cfg.Redis.DialTimeout),
+		envDuration("REDIS_READ_TIMEOUT", &cfg.Redis.ReadTimeout),
+		envDuration("REDIS_WRITE_TIMEOUT", &cfg.Redis.WriteTimeout),
+		envInt("REDIS_MAX_RETRIES", &cfg.Redis.MaxRetries),
+		envDuration("REDIS_MIN_RETRY_BACKOFF", &cfg.Redis.MinRetryBackoff),
+		envDuration("REDIS_MAX_RETRY_BACKOFF", &cfg.Redis.MaxRetryBackoff),
+	)
+	cfg.Redis.CacheBackend = os.Getenv("CACHE_BACKEND")
+	cfg.Redis.CacheEncoding = os.Getenv("REDIS_CACHE_ENCODING")
+	errs = append(errs,
+		envInt("REDIS_COMPRESS_THRESHOLD", &cfg.Redis.CompressThreshold),
+		envDuration("REDIS_CACHE_SOFT_TTL", &cfg.Redis.CacheSoftTTL),
+		envDuration("REDIS_CACHE_HARD_TTL", &cfg.Redis.CacheHardTTL),
+		envInt("REDIS_BREAKER_FAILURES", &cfg.Redis.BreakerFailures),
+		envDuration("REDIS_BREAKER_OPEN_TIMEOUT", &cfg.Redis.BreakerOpenTimeout),
+		envInt("REDIS_BREAKER_HALF_OPEN_PROBES", &cfg.Redis.BreakerHalfOpenProbes),
+	)
+	if cfg.Redis.CacheHardTTL == 0 {
+		cfg.Redis.CacheHardTTL = DefaultCacheHardTTL
 	}
 
 	cfg.Postgres.Host = os.Getenv("POSTGRES_HOST")
@@ -79,20 +264,451 @@ func LoadConfig() (*Config, error) {
 		cfg.Kafka.BrokerList = splitAndTrim(kafkaBrokers, ",")
 	}
 	cfg.Kafka.Topic = os.Getenv("KAFKA_TOPIC")
+	cfg.Kafka.LagThreshold = DefaultLagThreshold
+	errs = append(errs,
+		envDuration("KAFKA_INITIAL_BACKOFF", &cfg.Kafka.InitialBackoff),
+		envInt("KAFKA_MAX_RETRIES", &cfg.Kafka.MaxRetries),
+		envDuration("KAFKA_COMMIT_INTERVAL", &cfg.Kafka.CommitInterval),
+		envInt("KAFKA_BATCH_SIZE", &cfg.Kafka.BatchSize),
+		envDuration("KAFKA_BATCH_LINGER", &cfg.Kafka.BatchLinger),
+		envInt("KAFKA_WORKERS", &cfg.Kafka.Workers),
+		envDurations("KAFKA_RETRY_DELAYS", &cfg.Kafka.RetryDelays),
+		envInt("KAFKA_LAG_THRESHOLD", &cfg.Kafka.LagThreshold),
+		envDuration("KAFKA_MAX_BACKOFF", &cfg.Kafka.MaxBackoff),
+	)
+	if cfg.Kafka.MaxBackoff == 0 {
+		cfg.Kafka.MaxBackoff = DefaultMaxBackoff
+	}
+	cfg.Kafka.ExactlyOnce = true
+	errs = append(errs, envBool("KAFKA_EXACTLY_ONCE", &cfg.Kafka.ExactlyOnce))
+	cfg.Kafka.FatalErrorPolicy = os.Getenv("KAFKA_FATAL_ERROR_POLICY")
+	cfg.Kafka.MessageErrorPolicy = os.Getenv("KAFKA_MESSAGE_ERROR_POLICY")
+	if cfg.Kafka.BatchLinger == 0 {
+		cfg.Kafka.BatchLinger = DefaultBatchLinger
+	}
+	cfg.Kafka.OrderingKey = os.Getenv("KAFKA_ORDERING_KEY")
+	cfg.Kafka.ConsumerGroup = os.Getenv("KAFKA_CONSUMER_GROUP")
+	cfg.Kafka.InitialOffset = os.Getenv("KAFKA_INITIAL_OFFSET")
+	cfg.Kafka.RebalanceStrategy = os.Getenv("KAFKA_REBALANCE_STRATEGY")
+	errs = append(errs, envMap("KAFKA_TOPIC_HANDLERS", &cfg.Kafka.TopicHandlers))
+	cfg.Kafka.EventsTopic = os.Getenv("KAFKA_EVENTS_TOPIC")
+	cfg.Kafka.EventsAcks = os.Getenv("KAFKA_EVENTS_ACKS")
+	cfg.Kafka.EventsIdempotent = true
+	errs = append(errs, envBool("KAFKA_EVENTS_IDEMPOTENT", &cfg.Kafka.EventsIdempotent))
+	cfg.Kafka.SchemaRegistryURL = os.Getenv("SCHEMA_REGISTRY_URL")
+	cfg.Kafka.SchemaRegistryDir = os.Getenv("SCHEMA_REGISTRY_DIR")
+	cfg.Kafka.DLQTopic = os.Getenv("KAFKA_DLQ_TOPIC")
+	if cfg.Kafka.DLQTopic == "" && cfg.Kafka.Topic != "" {
+		cfg.Kafka.DLQTopic = cfg.Kafka.Topic + ".dlq"
+	}
+	errs = append(errs, cfg.Kafka.KafkaClientConfig.load()...)
+
+	cfg.Rules.File = os.Getenv("RULES_FILE")
+	cfg.Rules.ReloadInterval = DefaultRulesReloadInterval
+	errs = append(errs, envDuration("RULES_RELOAD_INTERVAL", &cfg.Rules.ReloadInterval))
 
-	if backoffStr := os.Getenv("KAFKA_INITIAL_BACKOFF"); backoffStr != "" {
-		if d, err := time.ParseDuration(backoffStr); err == nil {
-			cfg.Kafka.InitialBackoff = d
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadKafkaClient загружает только настройки подключения к Kafka, например
+// для подкоманды producer
+func LoadKafkaClient() (KafkaClientConfig, error) {
+	_ = godotenv.Load()
+
+	var cfg KafkaClientConfig
+	if err := errors.Join(cfg.load()...); err != nil {
+		return cfg, fmt.Errorf("config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *KafkaClientConfig) load() []error {
+	c.ClientID = os.Getenv("KAFKA_CLIENT_ID")
+	c.Version = os.Getenv("KAFKA_VERSION")
+	c.TLSCAFile = os.Getenv("KAFKA_TLS_CA_FILE")
+	c.TLSCertFile = os.Getenv("KAFKA_TLS_CERT_FILE")
+	c.TLSKeyFile = os.Getenv("KAFKA_TLS_KEY_FILE")
+	c.SASLMechanism = os.Getenv("KAFKA_SASL_MECHANISM")
+	c.SASLUsername = os.Getenv("KAFKA_SASL_USERNAME")
+	c.SASLPassword = os.Getenv("KAFKA_SASL_PASSWORD")
+	return []error{
+		envBool("KAFKA_TLS", &c.TLSEnabled),
+		envBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", &c.TLSInsecureSkipVerify),
+		envDuration("KAFKA_DIAL_TIMEOUT", &c.DialTimeout),
+		envInt("KAFKA_FETCH_MIN_BYTES", &c.FetchMinBytes),
+		envInt("KAFKA_FETCH_DEFAULT_BYTES", &c.FetchDefaultBytes),
+		envInt("KAFKA_FETCH_MAX_BYTES", &c.FetchMaxBytes),
+		envDuration("KAFKA_SESSION_TIMEOUT", &c.SessionTimeout),
+		envDuration("KAFKA_HEARTBEAT_INTERVAL", &c.HeartbeatInterval),
+	}
+}
+
+// Validate проверяет согласованность настроек
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Redis.Validate(),
+		c.Kafka.Validate(),
+		c.Rules.Validate(),
+	)
+}
+
+// Validate проверяет настройки правил
+func (c *RulesConfig) Validate() error {
+	if c.ReloadInterval < 0 {
+		return fmt.Errorf("RULES_RELOAD_INTERVAL must not be negative, got %s", c.ReloadInterval)
+	}
+	return nil
+}
+
+// Validate проверяет настройки Kafka
+func (c *KafkaConfig) Validate() error {
+	var errs []error
+	if len(c.BrokerList) == 0 {
+		errs = append(errs, errors.New("KAFKA_BROKER_LIST is required"))
+	}
+	if c.Topic == "" {
+		errs = append(errs, errors.New("KAFKA_TOPIC is required"))
+	}
+	if c.ConsumerGroup == "" {
+		errs = append(errs, errors.New("KAFKA_CONSUMER_GROUP is required"))
+	}
+	if c.DLQTopic != "" && c.DLQTopic == c.Topic {
+		errs = append(errs, errors.New("KAFKA_DLQ_TOPIC must differ from KAFKA_TOPIC"))
+	}
+	switch c.InitialOffset {
+	case "", "oldest", "newest":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_INITIAL_OFFSET must be oldest or newest, got %q", c.InitialOffset))
+	}
+	switch c.RebalanceStrategy {
+	case "", "range", "roundrobin", "sticky":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_REBALANCE_STRATEGY must be range, roundrobin or sticky, got %q", c.RebalanceStrategy))
+	}
+	if c.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_MAX_RETRIES must be >= 0, got %d", c.MaxRetries))
+	}
+	if c.InitialBackoff < 0 || c.CommitInterval < 0 {
+		errs = append(errs, errors.New("KAFKA_INITIAL_BACKOFF and KAFKA_COMMIT_INTERVAL must be >= 0"))
+	}
+	switch c.EventsAcks {
+	case "", "all", "leader", "none":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_EVENTS_ACKS must be all, leader or none, got %q", c.EventsAcks))
+	}
+	if c.EventsIdempotent && c.EventsAcks != "" && c.EventsAcks != "all" {
+		errs = append(errs, errors.New("KAFKA_EVENTS_IDEMPOTENT requires KAFKA_EVENTS_ACKS=all"))
+	}
+	if c.EventsTopic != "" && (c.EventsTopic == c.Topic || c.EventsTopic == c.DLQTopic) {
+		errs = append(errs, errors.New("KAFKA_EVENTS_TOPIC must differ from KAFKA_TOPIC and KAFKA_DLQ_TOPIC"))
+	}
+	for _, topic := range slices.Sorted(maps.Keys(c.TopicHandlers)) {
+		switch c.TopicHandlers[topic] {
+		case "payment", "delivery_status":
+		default:
+			errs = append(errs, fmt.Errorf("KAFKA_TOPIC_HANDLERS: %s handler must be payment or delivery_status, got %q", topic, c.TopicHandlers[topic]))
+		}
+		if topic == c.Topic || topic == c.DLQTopic || topic == c.EventsTopic {
+			errs = append(errs, fmt.Errorf("KAFKA_TOPIC_HANDLERS: %s must differ from KAFKA_TOPIC, KAFKA_DLQ_TOPIC and KAFKA_EVENTS_TOPIC", topic))
 		}
 	}
-	if maxRetriesStr := os.Getenv("KAFKA_MAX_RETRIES"); maxRetriesStr != "" {
-		if retries, err := strconv.Atoi(maxRetriesStr); err == nil {
-			cfg.Kafka.MaxRetries = retries
+	if c.SchemaRegistryURL != "" && c.SchemaRegistryDir != "" {
+		errs = append(errs, errors.New("SCHEMA_REGISTRY_URL and SCHEMA_REGISTRY_DIR are mutually exclusive"))
+	}
+	if c.BatchSize < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_BATCH_SIZE must be >= 0, got %d", c.BatchSize))
+	}
+	for i, d := range c.RetryDelays {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("KAFKA_RETRY_DELAYS must be > 0, got %s", d))
+		}
+		if i > 0 && d <= c.RetryDelays[i-1] {
+			errs = append(errs, errors.New("KAFKA_RETRY_DELAYS must be strictly increasing"))
+			break
 		}
 	}
-	cfg.Kafka.ConsumerGroup = os.Getenv("KAFKA_CONSUMER_GROUP")
+	if c.BatchLinger < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_BATCH_LINGER must be >= 0, got %s", c.BatchLinger))
+	}
+	if c.Workers < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_WORKERS must be >= 0, got %d", c.Workers))
+	}
+	if c.Workers > 1 && c.BatchSize > 1 {
+		errs = append(errs, errors.New("KAFKA_WORKERS and KAFKA_BATCH_SIZE are mutually exclusive"))
+	}
+	switch c.OrderingKey {
+	case "", "key", "order_uid", "customer_id":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_ORDERING_KEY must be key, order_uid or customer_id, got %q", c.OrderingKey))
+	}
+	if c.LagThreshold < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_LAG_THRESHOLD must be >= 0, got %d", c.LagThreshold))
+	}
+	if c.MaxBackoff < 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_MAX_BACKOFF must be >= 0, got %s", c.MaxBackoff))
+	}
+	switch c.FatalErrorPolicy {
+	case "", "restart", "exit":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_FATAL_ERROR_POLICY must be restart or exit, got %q", c.FatalErrorPolicy))
+	}
+	switch c.MessageErrorPolicy {
+	case "", "restart", "exit":
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_MESSAGE_ERROR_POLICY must be restart or exit, got %q", c.MessageErrorPolicy))
+	}
+	if err := c.KafkaClientConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
 
-	return cfg, nil
+// Validate проверяет настройки подключения к Kafka
+func (c *KafkaClientConfig) Validate() error {
+	var errs []error
+	if c.Version != "" {
+		if _, err := sarama.ParseKafkaVersion(c.Version); err != nil {
+			errs = append(errs, fmt.Errorf("KAFKA_VERSION: %w", err))
+		}
+	}
+
+	tlsFiles := c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSKeyFile != ""
+	if tlsFiles && !c.TLSEnabled {
+		errs = append(errs, errors.New("KAFKA_TLS_*_FILE settings require KAFKA_TLS=true"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("KAFKA_TLS_CERT_FILE and KAFKA_TLS_KEY_FILE must be set together"))
+	}
+	for _, f := range []struct{ name, path string }{
+		{"KAFKA_TLS_CA_FILE", c.TLSCAFile},
+		{"KAFKA_TLS_CERT_FILE", c.TLSCertFile},
+		{"KAFKA_TLS_KEY_FILE", c.TLSKeyFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name, err))
+		}
+	}
+
+	switch c.SASLMechanism {
+	case "":
+		if c.SASLUsername != "" || c.SASLPassword != "" {
+			errs = append(errs, errors.New("KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD require KAFKA_SASL_MECHANISM"))
+		}
+	case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		if c.SASLUsername == "" || c.SASLPassword == "" {
+			errs = append(errs, fmt.Errorf("KAFKA_SASL_MECHANISM=%s requires KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD", c.SASLMechanism))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_SASL_MECHANISM must be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512, got %q", c.SASLMechanism))
+	}
+
+	for _, v := range []struct {
+		name string
+		n    int
+	}{
+		{"KAFKA_FETCH_MIN_BYTES", c.FetchMinBytes},
+		{"KAFKA_FETCH_DEFAULT_BYTES", c.FetchDefaultBytes},
+		{"KAFKA_FETCH_MAX_BYTES", c.FetchMaxBytes},
+	} {
+		if v.n < 0 || v.n > math.MaxInt32 {
+			errs = append(errs, fmt.Errorf("%s must be between 0 and %d, got %d", v.name, math.MaxInt32, v.n))
+		}
+	}
+	if c.FetchMaxBytes > 0 && max(c.FetchMinBytes, c.FetchDefaultBytes) > c.FetchMaxBytes {
+		errs = append(errs, errors.New("KAFKA_FETCH_MIN_BYTES and KAFKA_FETCH_DEFAULT_BYTES must not exceed KAFKA_FETCH_MAX_BYTES"))
+	}
+
+	if c.DialTimeout < 0 || c.SessionTimeout < 0 || c.HeartbeatInterval < 0 {
+		errs = append(errs, errors.New("KAFKA_DIAL_TIMEOUT, KAFKA_SESSION_TIMEOUT and KAFKA_HEARTBEAT_INTERVAL must be >= 0"))
+	}
+	if c.SessionTimeout > 0 && c.HeartbeatInterval >= c.SessionTimeout {
+		errs = append(errs, errors.New("KAFKA_HEARTBEAT_INTERVAL must be less than KAFKA_SESSION_TIMEOUT"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет настройки подключения к Redis
+func (c *RedisConfig) Validate() error {
+	var errs []error
+	if len(c.Addrs) == 0 && c.CacheBackend != "memory" {
+		errs = append(errs, errors.New("REDIS_ADDRS is required"))
+	}
+	if c.DBRedis < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_DB must be >= 0, got %d", c.DBRedis))
+	}
+	if c.ClusterMode && c.SentinelMaster != "" {
+		errs = append(errs, errors.New("REDIS_CLUSTER and REDIS_SENTINEL_MASTER are mutually exclusive"))
+	}
+	if c.ClusterMode && c.DBRedis != 0 {
+		errs = append(errs, errors.New("REDIS_DB is not supported in cluster mode"))
+	}
+	if len(c.Addrs) > 1 && !c.ClusterMode && c.SentinelMaster == "" {
+		errs = append(errs, errors.New("multiple REDIS_ADDRS require REDIS_CLUSTER=true or REDIS_SENTINEL_MASTER"))
+	}
+
+	tlsFiles := c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSKeyFile != ""
+	if tlsFiles && !c.TLSEnabled {
+		errs = append(errs, errors.New("REDIS_TLS_*_FILE settings require REDIS_TLS=true"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE must be set together"))
+	}
+	for _, f := range []struct{ name, path string }{
+		{"REDIS_TLS_CA_FILE", c.TLSCAFile},
+		{"REDIS_TLS_CERT_FILE", c.TLSCertFile},
+		{"REDIS_TLS_KEY_FILE", c.TLSKeyFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name, err))
+		}
+	}
+
+	if c.PoolSize < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_POOL_SIZE must be >= 0, got %d", c.PoolSize))
+	}
+	if c.MinIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_MIN_IDLE_CONNS must be >= 0, got %d", c.MinIdleConns))
+	}
+	if c.PoolSize > 0 && c.MinIdleConns > c.PoolSize {
+		errs = append(errs, fmt.Errorf("REDIS_MIN_IDLE_CONNS (%d) must not exceed REDIS_POOL_SIZE (%d)", c.MinIdleConns, c.PoolSize))
+	}
+	for _, v := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"REDIS_DIAL_TIMEOUT", c.DialTimeout},
+		{"REDIS_READ_TIMEOUT", c.ReadTimeout},
+		{"REDIS_WRITE_TIMEOUT", c.WriteTimeout},
+		{"REDIS_MIN_RETRY_BACKOFF", c.MinRetryBackoff},
+		{"REDIS_MAX_RETRY_BACKOFF", c.MaxRetryBackoff},
+	} {
+		if v.d < 0 {
+			errs = append(errs, fmt.Errorf("%s must be >= 0, got %s", v.name, v.d))
+		}
+	}
+	if c.MaxRetryBackoff > 0 && c.MinRetryBackoff > c.MaxRetryBackoff {
+		errs = append(errs, errors.New("REDIS_MIN_RETRY_BACKOFF must not exceed REDIS_MAX_RETRY_BACKOFF"))
+	}
+	// -1 отключает повторы в go-redis
+	if c.MaxRetries < -1 {
+		errs = append(errs, fmt.Errorf("REDIS_MAX_RETRIES must be >= -1, got %d", c.MaxRetries))
+	}
+
+	switch c.CacheBackend {
+	case "", "redis", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("CACHE_BACKEND must be redis or memory, got %q", c.CacheBackend))
+	}
+	switch c.CacheEncoding {
+	case "", "json", "msgpack":
+	default:
+		errs = append(errs, fmt.Errorf("REDIS_CACHE_ENCODING must be json or msgpack, got %q", c.CacheEncoding))
+	}
+	if c.CompressThreshold < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_COMPRESS_THRESHOLD must be >= 0, got %d", c.CompressThreshold))
+	}
+	if c.BreakerFailures < 0 || c.BreakerHalfOpenProbes < 0 || c.BreakerOpenTimeout < 0 {
+		errs = append(errs, errors.New("REDIS_BREAKER_* settings must be >= 0"))
+	}
+	if c.CacheSoftTTL < 0 || c.CacheHardTTL < 0 {
+		errs = append(errs, errors.New("REDIS_CACHE_SOFT_TTL and REDIS_CACHE_HARD_TTL must be >= 0"))
+	}
+	if c.CacheSoftTTL > 0 && c.CacheHardTTL > 0 && c.CacheSoftTTL >= c.CacheHardTTL {
+		errs = append(errs, fmt.Errorf("REDIS_CACHE_SOFT_TTL (%s) must be less than REDIS_CACHE_HARD_TTL (%s)", c.CacheSoftTTL, c.CacheHardTTL))
+	}
+
+	return errors.Join(errs...)
+}
+
+func envInt(name string, dst *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: invalid integer %q", name, v)
+	}
+	*dst = i
+	return nil
+}
+
+func envBool(name string, dst *bool) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: invalid boolean %q", name, v)
+	}
+	*dst = b
+	return nil
+}
+
+func envDuration(name string, dst *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: invalid duration %q", name, v)
+	}
+	*dst = d
+	return nil
+}
+
+func envDurations(name string, dst *[]time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	var out []time.Duration
+	for _, part := range splitAndTrim(v, ",") {
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration %q", name, part)
+		}
+		out = append(out, d)
+	}
+	*dst = out
+	return nil
+}
+
+// envMap разбирает список пар key=value через запятую
+func envMap(name string, dst *map[string]string) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, part := range splitAndTrim(v, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return fmt.Errorf("%s: invalid pair %q, want key=value", name, part)
+		}
+		m[key] = value
+	}
+	*dst = m
+	return nil
 }
 
 func splitAndTrim(str, sep string) []string {
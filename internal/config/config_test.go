@@ -0,0 +1,161 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RedisConfig
+		wantErr string
+	}{
+		{name: "minimal", cfg: RedisConfig{Addrs: []string{"localhost:6379"}}},
+		{name: "no addrs", cfg: RedisConfig{}, wantErr: "REDIS_ADDRS is required"},
+		{
+			name:    "cluster with sentinel",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, ClusterMode: true, SentinelMaster: "mymaster"},
+			wantErr: "mutually exclusive",
+		},
+		{
+			name:    "cluster with db",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, ClusterMode: true, DBRedis: 2},
+			wantErr: "REDIS_DB is not supported in cluster mode",
+		},
+		{
+			name:    "multiple addrs without mode",
+			cfg:     RedisConfig{Addrs: []string{"a:1", "b:1"}},
+			wantErr: "multiple REDIS_ADDRS",
+		},
+		{
+			name:    "cert without key",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, TLSEnabled: true, TLSCertFile: "/nonexistent.pem"},
+			wantErr: "must be set together",
+		},
+		{
+			name:    "min idle exceeds pool",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, PoolSize: 2, MinIdleConns: 5},
+			wantErr: "must not exceed REDIS_POOL_SIZE",
+		},
+		{
+			name:    "negative timeout",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, ReadTimeout: -time.Second},
+			wantErr: "REDIS_READ_TIMEOUT must be >= 0",
+		},
+		{
+			name:    "soft ttl exceeds hard ttl",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, CacheSoftTTL: time.Minute, CacheHardTTL: time.Minute},
+			wantErr: "must be less than REDIS_CACHE_HARD_TTL",
+		},
+		{
+			name:    "unknown encoding",
+			cfg:     RedisConfig{Addrs: []string{"a:1"}, CacheEncoding: "xml"},
+			wantErr: "REDIS_CACHE_ENCODING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestKafkaConfig_Validate(t *testing.T) {
+	valid := KafkaConfig{BrokerList: []string{"kafka:9092"}, Topic: "orders", ConsumerGroup: "app"}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		mutate  func(*KafkaConfig)
+		wantErr string
+	}{
+		{name: "no group", mutate: func(c *KafkaConfig) { c.ConsumerGroup = "" }, wantErr: "KAFKA_CONSUMER_GROUP is required"},
+		{name: "bad offset", mutate: func(c *KafkaConfig) { c.InitialOffset = "latest" }, wantErr: "KAFKA_INITIAL_OFFSET"},
+		{name: "bad strategy", mutate: func(c *KafkaConfig) { c.RebalanceStrategy = "random" }, wantErr: "KAFKA_REBALANCE_STRATEGY"},
+		{name: "negative retries", mutate: func(c *KafkaConfig) { c.MaxRetries = -1 }, wantErr: "KAFKA_MAX_RETRIES"},
+		{
+			name:    "idempotent events without acks all",
+			mutate:  func(c *KafkaConfig) { c.EventsIdempotent, c.EventsAcks = true, "leader" },
+			wantErr: "KAFKA_EVENTS_IDEMPOTENT requires KAFKA_EVENTS_ACKS=all",
+		},
+		{
+			name:    "workers with batches",
+			mutate:  func(c *KafkaConfig) { c.Workers, c.BatchSize = 4, 100 },
+			wantErr: "KAFKA_WORKERS and KAFKA_BATCH_SIZE are mutually exclusive",
+		},
+		{name: "negative batch", mutate: func(c *KafkaConfig) { c.BatchSize = -5 }, wantErr: "KAFKA_BATCH_SIZE"},
+		{name: "bad fatal error policy", mutate: func(c *KafkaConfig) { c.FatalErrorPolicy = "ignore" }, wantErr: "KAFKA_FATAL_ERROR_POLICY"},
+		{name: "bad message error policy", mutate: func(c *KafkaConfig) { c.MessageErrorPolicy = "skip" }, wantErr: "KAFKA_MESSAGE_ERROR_POLICY"},
+		{
+			name:    "unordered retry delays",
+			mutate:  func(c *KafkaConfig) { c.RetryDelays = []time.Duration{time.Minute, 10 * time.Second} },
+			wantErr: "strictly increasing",
+		},
+		{
+			name:    "unknown topic handler",
+			mutate:  func(c *KafkaConfig) { c.TopicHandlers = map[string]string{"payments": "refund"} },
+			wantErr: "payments handler must be payment or delivery_status",
+		},
+		{
+			name:    "handler on orders topic",
+			mutate:  func(c *KafkaConfig) { c.TopicHandlers = map[string]string{"orders": "payment"} },
+			wantErr: "orders must differ from KAFKA_TOPIC",
+		},
+		{name: "bad version", mutate: func(c *KafkaConfig) { c.Version = "three" }, wantErr: "KAFKA_VERSION"},
+		{
+			name:    "tls files without tls",
+			mutate:  func(c *KafkaConfig) { c.TLSCAFile = "/nonexistent.pem" },
+			wantErr: "KAFKA_TLS_*_FILE settings require KAFKA_TLS=true",
+		},
+		{
+			name:    "sasl without password",
+			mutate:  func(c *KafkaConfig) { c.SASLMechanism, c.SASLUsername = "SCRAM-SHA-512", "app" },
+			wantErr: "requires KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD",
+		},
+		{name: "unknown sasl mechanism", mutate: func(c *KafkaConfig) { c.SASLMechanism = "GSSAPI" }, wantErr: "KAFKA_SASL_MECHANISM"},
+		{
+			name:    "heartbeat exceeds session",
+			mutate:  func(c *KafkaConfig) { c.SessionTimeout, c.HeartbeatInterval = 10*time.Second, 10*time.Second },
+			wantErr: "KAFKA_HEARTBEAT_INTERVAL must be less than KAFKA_SESSION_TIMEOUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.mutate(&cfg)
+			assert.ErrorContains(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestEnvParsers(t *testing.T) {
+	t.Setenv("TEST_INT", "abc")
+	var i int
+	assert.ErrorContains(t, envInt("TEST_INT", &i), "TEST_INT: invalid integer")
+
+	t.Setenv("TEST_DURATION", "5s")
+	var d time.Duration
+	assert.NoError(t, envDuration("TEST_DURATION", &d))
+	assert.Equal(t, 5*time.Second, d)
+
+	var b bool
+	assert.NoError(t, envBool("TEST_UNSET_BOOL", &b))
+	assert.False(t, b)
+
+	t.Setenv("TEST_MAP", "payments=payment, deliveries = delivery_status")
+	var m map[string]string
+	assert.NoError(t, envMap("TEST_MAP", &m))
+	assert.Equal(t, map[string]string{"payments": "payment", "deliveries": "delivery_status"}, m)
+	t.Setenv("TEST_MAP", "payments")
+	assert.ErrorContains(t, envMap("TEST_MAP", &m), "want key=value")
+}
@@ -1,5 +1,9 @@
 package domain
 
+// OrderSchemaVersion версия структуры Order в кэше и сериализованных данных.
+// Увеличивается при любом изменении полей Order.
+const OrderSchemaVersion = 2
+
 type Delivery struct {
 	Name    string `json:"name" validate:"required"`
 	Phone   string `json:"phone" validate:"required,e164"`
@@ -55,4 +59,5 @@ type Payment struct {
 	Bank         string `json:"bank" validate:"required"`
 	DeliveryCost int    `json:"delivery_cost" validate:"required,min=0"`
 	GoodsTotal   int    `json:"goods_total" validate:"required,min=0"`
+	CustomFee    int    `json:"custom_fee" validate:"min=0"`
 }
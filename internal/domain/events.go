@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Типы событий заказа
+const (
+	EventOrderCreated = "OrderCreated"
+)
+
+// EventSchemaVersion версия конверта события и его данных.
+// Увеличивается при несовместимом изменении формата.
+const EventSchemaVersion = 1
+
+// Event конверт доменного события для внешних потребителей
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Key ключ партиционирования: события одного заказа идут по порядку
+	Key  string `json:"key"`
+	Data any    `json:"data"`
+}
+
+// OrderCreated данные события о принятом заказе
+type OrderCreated struct {
+	OrderID int   `json:"order_id"`
+	Order   Order `json:"order"`
+}
+
+// NewOrderCreatedEvent событие о сохранённом заказе
+func NewOrderCreatedEvent(id int, order Order) Event {
+	return Event{
+		ID:         newEventID(),
+		Type:       EventOrderCreated,
+		Version:    EventSchemaVersion,
+		OccurredAt: time.Now().UTC(),
+		Key:        order.OrderUID,
+		Data:       OrderCreated{OrderID: id, Order: order},
+	}
+}
+
+// newEventID случайный идентификатор в формате UUID v4
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
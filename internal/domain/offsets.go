@@ -0,0 +1,35 @@
+package domain
+
+import "context"
+
+// MessageOffset позиция сообщения Kafka в группе потребителей
+type MessageOffset struct {
+	Group     string
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// PartitionOffsets позиция партиции в БД
+type PartitionOffsets struct {
+	// Next следующее к обработке смещение, всё до него обработано;
+	// -1 — позиция ещё не сохранена
+	Next int64
+	// Processed обработанные сообщения после Next: параллельные обработчики
+	// завершают сообщения не по порядку смещений
+	Processed []int64
+}
+
+type messageOffsetKey struct{}
+
+// WithMessageOffsets ctx обработки сообщений одной партиции: хранилище
+// отмечает их обработанными в той же транзакции, что и их данные
+func WithMessageOffsets(ctx context.Context, offsets ...MessageOffset) context.Context {
+	return context.WithValue(ctx, messageOffsetKey{}, offsets)
+}
+
+// MessageOffsetsFrom позиции сообщений, которые обрабатываются в ctx
+func MessageOffsetsFrom(ctx context.Context) []MessageOffset {
+	offsets, _ := ctx.Value(messageOffsetKey{}).([]MessageOffset)
+	return offsets
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// QuarantineStatus состояние сообщения в карантине
+type QuarantineStatus string
+
+const (
+	QuarantinePending   QuarantineStatus = "pending"
+	QuarantineReplaying QuarantineStatus = "replaying"
+	QuarantineReplayed  QuarantineStatus = "replayed"
+	QuarantineDiscarded QuarantineStatus = "discarded"
+)
+
+// Violation нарушение правила проверки заказа. Expected и Actual заданы у
+// правил, сравнивающих значения.
+type Violation struct {
+	Field    string `json:"field"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Expected any    `json:"expected,omitempty"`
+	Actual   any    `json:"actual,omitempty"`
+}
+
+// QuarantinedMessage сообщение, которое не удалось обработать, вместе с причиной
+type QuarantinedMessage struct {
+	ID            int64             `json:"id"`
+	Topic         string            `json:"topic"`
+	Partition     int32             `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Key           string            `json:"key,omitempty"`
+	Payload       string            `json:"payload"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Stage         string            `json:"stage"`
+	Reason        string            `json:"reason"`
+	Violations    []Violation       `json:"violations,omitempty"`
+	Attempts      int               `json:"attempts"`
+	Status        QuarantineStatus  `json:"status"`
+	DiscardReason string            `json:"discard_reason,omitempty"`
+	OrderID       *int              `json:"order_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	ResolvedAt    *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// QuarantineFilter отбор сообщений карантина; пустые поля не ограничивают выборку
+type QuarantineFilter struct {
+	Status QuarantineStatus
+	Stage  string
+	Topic  string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Статусы подтверждения оплаты
+const (
+	PaymentConfirmed = "confirmed"
+	PaymentDeclined  = "declined"
+	PaymentRefunded  = "refunded"
+)
+
+// PaymentConfirmation подтверждение оплаты заказа от платёжного сервиса
+type PaymentConfirmation struct {
+	OrderUID    string    `json:"order_uid" validate:"required"`
+	Transaction string    `json:"transaction" validate:"required"`
+	Status      string    `json:"status" validate:"required,oneof=confirmed declined refunded"`
+	Amount      int       `json:"amount" validate:"min=0"`
+	Currency    string    `json:"currency" validate:"required,len=3"`
+	ConfirmedAt time.Time `json:"confirmed_at" validate:"required"`
+}
+
+// DeliveryStatusUpdate изменение статуса доставки заказа от службы доставки
+type DeliveryStatusUpdate struct {
+	OrderUID    string    `json:"order_uid" validate:"required"`
+	TrackNumber string    `json:"track_number" validate:"required"`
+	Status      string    `json:"status" validate:"required,oneof=accepted in_transit ready_for_pickup delivered returned lost"`
+	Location    string    `json:"location"`
+	UpdatedAt   time.Time `json:"updated_at" validate:"required"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Правила согласованности заказа
+const (
+	RuleGoodsTotal  = "goods_total"
+	RuleAmount      = "amount"
+	RuleItemTotal   = "item_total_price"
+	RuleTransaction = "transaction"
+)
+
+// ValidationError заказ нарушает правила проверки
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msg := v.Message
+		if v.Field != "" {
+			msg = v.Field + ": " + msg
+		}
+		// идентификатор правила попадает в причину отказа в DLQ и карантине
+		msgs[i] = msg + " [" + v.Rule + "]"
+	}
+	return "order validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidateOrder проверяет согласованность полей заказа, которую не выразить
+// тегами validate: суммы позиций и оплаты, цену со скидкой и номер
+// транзакции. Возвращает *ValidationError со всеми нарушениями.
+func ValidateOrder(o Order) error {
+	var vs []Violation
+
+	goods := 0
+	for i, it := range o.Items {
+		goods += it.TotalPrice
+		// скидка в процентах, копейки отбрасываются
+		if want := it.Price * (100 - it.Sale) / 100; it.TotalPrice != want {
+			vs = append(vs, Violation{
+				Field:    fmt.Sprintf("Items[%d].TotalPrice", i),
+				Rule:     RuleItemTotal,
+				Message:  fmt.Sprintf("total_price must be price %d with sale %d%% applied", it.Price, it.Sale),
+				Expected: want,
+				Actual:   it.TotalPrice,
+			})
+		}
+	}
+
+	p := o.Payment
+	if p.GoodsTotal != goods {
+		vs = append(vs, Violation{
+			Field:    "Payment.GoodsTotal",
+			Rule:     RuleGoodsTotal,
+			Message:  "goods_total must equal the sum of items total_price",
+			Expected: goods,
+			Actual:   p.GoodsTotal,
+		})
+	}
+	if want := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != want {
+		vs = append(vs, Violation{
+			Field:    "Payment.Amount",
+			Rule:     RuleAmount,
+			Message:  "amount must equal goods_total + delivery_cost + custom_fee",
+			Expected: want,
+			Actual:   p.Amount,
+		})
+	}
+	if p.Transaction != o.OrderUID {
+		vs = append(vs, Violation{
+			Field:    "Payment.Transaction",
+			Rule:     RuleTransaction,
+			Message:  "transaction must equal order_uid",
+			Expected: o.OrderUID,
+			Actual:   p.Transaction,
+		})
+	}
+
+	if len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOrder(t *testing.T) {
+	model, err := os.ReadFile("../../model.json")
+	require.NoError(t, err)
+	var valid Order
+	require.NoError(t, json.Unmarshal(model, &valid))
+	require.NoError(t, ValidateOrder(valid))
+
+	tests := []struct {
+		name   string
+		mutate func(*Order)
+		want   []Violation
+	}{
+		{
+			name:   "custom fee in amount",
+			mutate: func(o *Order) { o.Payment.CustomFee, o.Payment.Amount = 100, 1917 },
+		},
+		{
+			name:   "item total without sale",
+			mutate: func(o *Order) { o.Items[0].TotalPrice, o.Payment.GoodsTotal, o.Payment.Amount = 453, 453, 1953 },
+			want: []Violation{{
+				Field: "Items[0].TotalPrice", Rule: RuleItemTotal, Expected: 317, Actual: 453,
+				Message: "total_price must be price 453 with sale 30% applied",
+			}},
+		},
+		{
+			name:   "goods total and amount",
+			mutate: func(o *Order) { o.Payment.GoodsTotal = 300 },
+			want: []Violation{
+				{Field: "Payment.GoodsTotal", Rule: RuleGoodsTotal, Expected: 317, Actual: 300, Message: "goods_total must equal the sum of items total_price"},
+				{Field: "Payment.Amount", Rule: RuleAmount, Expected: 1800, Actual: 1817, Message: "amount must equal goods_total + delivery_cost + custom_fee"},
+			},
+		},
+		{
+			name:   "foreign transaction",
+			mutate: func(o *Order) { o.Payment.Transaction = "other" },
+			want: []Violation{{
+				Field: "Payment.Transaction", Rule: RuleTransaction, Expected: "b563feb7b2b84b6test", Actual: "other",
+				Message: "transaction must equal order_uid",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid
+			o.Items = append([]Items(nil), valid.Items...)
+			tt.mutate(&o)
+
+			err := ValidateOrder(o)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.want, verr.Violations)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"l0/internal/cache"
+	"l0/pkg/e"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=admin.go -destination=mocks/admin_mock.go
+
+// CacheStatsProvider источник статистики слоёв кэша
+type CacheStatsProvider interface {
+	CacheStats() []cache.LayerStats
+}
+
+// CacheAdmin операции администрирования кэша заказов, реализуется cache.Cache
+type CacheAdmin interface {
+	CacheStatsProvider
+	Inspect(ctx context.Context, key string) (cache.Entry, error)
+	Evict(ctx context.Context, key string) (bool, error)
+	Flush(ctx context.Context) (int64, error)
+}
+
+// Обертка для swagger ответа со статистикой кэша
+type CacheStatsResponse struct {
+	Layers []cache.LayerStats `json:"layers"`
+}
+
+// Обертка для swagger ответа об удалении из кэша
+type CacheEvictResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+type AdminHandler struct {
+	cacheAdmin CacheAdmin
+	// extraStats дополнительные слои кэша, например кэш готовых ответов
+	extraStats []CacheStatsProvider
+	logger     *slog.Logger
+}
+
+func NewAdminHandler(logger *slog.Logger, cacheAdmin CacheAdmin, extraStats ...CacheStatsProvider) *AdminHandler {
+	return &AdminHandler{
+		cacheAdmin: cacheAdmin,
+		extraStats: extraStats,
+		logger:     logger,
+	}
+}
+
+// AdminAuth проверяет токен администратора из заголовка Authorization: Bearer <token>
+// или X-Admin-Token. Без настроенного токена административный API отключён.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "Admin API is disabled"})
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Token")
+		if auth := c.GetHeader("Authorization"); provided == "" && strings.HasPrefix(auth, "Bearer ") {
+			provided = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CacheStats godoc
+// @Summary Статистика кэша
+// @Description Возвращает количество попаданий, промахов, ошибок и долю попаданий по слоям кэша
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} handler.CacheStatsResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Router /admin/cache/stats [get]
+func (h *AdminHandler) CacheStats(c *gin.Context) {
+	layers := h.cacheAdmin.CacheStats()
+	for _, p := range h.extraStats {
+		layers = append(layers, p.CacheStats()...)
+	}
+	c.JSON(http.StatusOK, CacheStatsResponse{Layers: layers})
+}
+
+// InspectCachedOrder godoc
+// @Summary Просмотр заказа в кэше
+// @Description Возвращает сохранённое в кэше значение заказа, его TTL и формат записи
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "ID заказа"
+// @Success 200 {object} cache.Entry
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 404 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/cache/orders/{id} [get]
+func (h *AdminHandler) InspectCachedOrder(c *gin.Context) {
+	id, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.cacheAdmin.Inspect(c.Request.Context(), cache.OrderKey(id))
+	if err != nil {
+		if errors.Is(err, e.ErrCacheMiss) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Order is not cached"})
+			return
+		}
+		h.logger.Error("Failed to inspect cached order", slog.Int("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// EvictCachedOrder godoc
+// @Summary Удалить заказ из кэша
+// @Security AdminToken
+// @Param id path int true "ID заказа"
+// @Success 200 {object} handler.CacheEvictResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/cache/orders/{id} [delete]
+func (h *AdminHandler) EvictCachedOrder(c *gin.Context) {
+	id, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.cacheAdmin.Evict(c.Request.Context(), cache.OrderKey(id))
+	if err == nil {
+		// вместе с заказом удаляем готовый ответ API
+		_, err = h.cacheAdmin.Evict(c.Request.Context(), cache.OrderResponseKey(id))
+	}
+	if err != nil {
+		h.logger.Error("Failed to evict cached order", slog.Int("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Order evicted from cache", slog.Int("id", id), slog.Bool("existed", deleted))
+	resp := CacheEvictResponse{}
+	if deleted {
+		resp.Deleted = 1
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// FlushCachedOrders godoc
+// @Summary Очистить кэш заказов
+// @Description Удаляет все ключи пространства заказов (в Redis через SCAN), не затрагивая остальные данные
+// @Security AdminToken
+// @Success 200 {object} handler.CacheEvictResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/cache/orders [delete]
+func (h *AdminHandler) FlushCachedOrders(c *gin.Context) {
+	deleted, err := h.cacheAdmin.Flush(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to flush order cache", slog.Int64("deleted", deleted), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Order cache flushed", slog.Int64("deleted", deleted))
+	c.JSON(http.StatusOK, CacheEvictResponse{Deleted: deleted})
+}
+
+func (h *AdminHandler) orderID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Error("Invalid order id", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid order ID"})
+		return 0, false
+	}
+	return id, true
+}
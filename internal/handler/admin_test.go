@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"l0/internal/cache"
+	"l0/internal/config"
+	mock_handler "l0/internal/handler/mocks"
+	"l0/pkg/e"
+
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAdminToken = "secret"
+
+func setupAdminRouter(logger *slog.Logger, token string, mockCacheAdmin *mock_handler.MockCacheAdmin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAdminHandler(logger, mockCacheAdmin)
+	r := gin.New()
+	g := r.Group("/admin", AdminAuth(token))
+	g.GET("/cache/stats", h.CacheStats)
+	g.GET("/cache/orders/:id", h.InspectCachedOrder)
+	g.DELETE("/cache/orders/:id", h.EvictCachedOrder)
+	g.DELETE("/cache/orders", h.FlushCachedOrders)
+	return r
+}
+
+func TestAdminAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCacheAdmin := mock_handler.NewMockCacheAdmin(ctrl)
+	mockCacheAdmin.EXPECT().CacheStats().Return(nil).Times(2)
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		value  string
+		code   int
+	}{
+		{name: "disabled", token: "", header: "X-Admin-Token", value: "", code: http.StatusForbidden},
+		{name: "missing", token: testAdminToken, code: http.StatusUnauthorized},
+		{name: "wrong", token: testAdminToken, header: "X-Admin-Token", value: "nope", code: http.StatusUnauthorized},
+		{name: "header", token: testAdminToken, header: "X-Admin-Token", value: testAdminToken, code: http.StatusOK},
+		{name: "bearer", token: testAdminToken, header: "Authorization", value: "Bearer " + testAdminToken, code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAdminRouter(slog.Default(), tt.token, mockCacheAdmin)
+			req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_CacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCacheAdmin := mock_handler.NewMockCacheAdmin(ctrl)
+	mockCacheAdmin.EXPECT().CacheStats().Return([]cache.LayerStats{
+		{Layer: "redis", Hits: 3, Misses: 1, HitRatio: 0.75},
+	})
+
+	r := setupAdminRouter(slog.Default(), testAdminToken, mockCacheAdmin)
+	req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"hit_ratio":0.75`)
+}
+
+func TestAdminHandler_InspectCachedOrder_NotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCacheAdmin := mock_handler.NewMockCacheAdmin(ctrl)
+	mockCacheAdmin.EXPECT().Inspect(gomock.Any(), "7").Return(cache.Entry{}, e.ErrCacheMiss)
+
+	r := setupAdminRouter(slog.Default(), testAdminToken, mockCacheAdmin)
+	req := httptest.NewRequest(http.MethodGet, "/admin/cache/orders/7", nil)
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminHandler_FlushCachedOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCacheAdmin := mock_handler.NewMockCacheAdmin(ctrl)
+	mockCacheAdmin.EXPECT().Flush(gomock.Any()).Return(int64(42), nil)
+
+	r := setupAdminRouter(slog.Default(), testAdminToken, mockCacheAdmin)
+	req := httptest.NewRequest(http.MethodDelete, "/admin/cache/orders", nil)
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":42`)
+}
+
+func TestInitRouter_AdminRequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	r := InitRouter(context.Background(), cfg, slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "admin routes are not mounted without a token")
+
+	cfg.Http.AdminToken = testAdminToken
+	r = InitRouter(context.Background(), cfg, slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
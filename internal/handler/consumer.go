@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"l0/internal/kafka"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=consumer.go -destination=mocks/consumer_mock.go
+
+// ConsumerControl управление чтением Kafka, реализуется kafka.ConsumerControl
+type ConsumerControl interface {
+	Partitions() []kafka.PartitionState
+	Pause(topic string, partitions []int32) error
+	Resume(topic string, partitions []int32) error
+	Seek(topic string, partition int32, offset int64) (int64, error)
+	ReplayFrom(topic string, partitions []int32, at time.Time) (map[int32]int64, error)
+}
+
+// Обертка для swagger ответа с состоянием партиций
+type ConsumerStateResponse struct {
+	Partitions []kafka.PartitionState `json:"partitions"`
+}
+
+// Запрос на паузу или возобновление; без partitions — все назначенные партиции топика
+type ConsumerPartitionsRequest struct {
+	Topic      string  `json:"topic" binding:"required"`
+	Partitions []int32 `json:"partitions"`
+}
+
+// Запрос на перемотку партиции; -2 — начало партиции, -1 — конец
+type ConsumerSeekRequest struct {
+	Topic     string `json:"topic" binding:"required"`
+	Partition *int32 `json:"partition" binding:"required"`
+	Offset    *int64 `json:"offset" binding:"required"`
+}
+
+// Запрос на повторное чтение с момента времени
+type ConsumerReplayRequest struct {
+	Topic      string    `json:"topic" binding:"required"`
+	Partitions []int32   `json:"partitions"`
+	Timestamp  time.Time `json:"timestamp" binding:"required"`
+}
+
+// Ответ с новыми смещениями партиций
+type ConsumerSeekResponse struct {
+	Topic   string          `json:"topic"`
+	Offsets map[int32]int64 `json:"offsets"`
+}
+
+type ConsumerHandler struct {
+	control ConsumerControl
+	logger  *slog.Logger
+}
+
+func NewConsumerHandler(logger *slog.Logger, control ConsumerControl) *ConsumerHandler {
+	return &ConsumerHandler{
+		control: control,
+		logger:  logger,
+	}
+}
+
+// ConsumerState godoc
+// @Summary Состояние чтения Kafka
+// @Description Позиция, отставание и состояние каждой партиции, назначенной этому экземпляру
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} handler.ConsumerStateResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Router /admin/consumer [get]
+func (h *ConsumerHandler) ConsumerState(c *gin.Context) {
+	c.JSON(http.StatusOK, ConsumerStateResponse{Partitions: h.control.Partitions()})
+}
+
+// PauseConsumer godoc
+// @Summary Приостановить чтение партиций
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.ConsumerPartitionsRequest true "Топик и партиции"
+// @Success 200 {object} handler.ConsumerStateResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Router /admin/consumer/pause [post]
+func (h *ConsumerHandler) PauseConsumer(c *gin.Context) {
+	h.setPaused(c, h.control.Pause)
+}
+
+// ResumeConsumer godoc
+// @Summary Возобновить чтение партиций
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.ConsumerPartitionsRequest true "Топик и партиции"
+// @Success 200 {object} handler.ConsumerStateResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Router /admin/consumer/resume [post]
+func (h *ConsumerHandler) ResumeConsumer(c *gin.Context) {
+	h.setPaused(c, h.control.Resume)
+}
+
+func (h *ConsumerHandler) setPaused(c *gin.Context, apply func(topic string, partitions []int32) error) {
+	var req ConsumerPartitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+		return
+	}
+	if err := apply(req.Topic, req.Partitions); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ConsumerStateResponse{Partitions: h.control.Partitions()})
+}
+
+// SeekConsumer godoc
+// @Summary Перемотать партицию
+// @Description Чтение партиции продолжится с указанного смещения после перезапуска сессии группы
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.ConsumerSeekRequest true "Партиция и смещение"
+// @Success 200 {object} handler.ConsumerSeekResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/consumer/seek [post]
+func (h *ConsumerHandler) SeekConsumer(c *gin.Context) {
+	var req ConsumerSeekRequest
+	if err := c.ShouldBindJSON(&req); err != nil || *req.Offset < -2 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+		return
+	}
+	offset, err := h.control.Seek(req.Topic, *req.Partition, *req.Offset)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ConsumerSeekResponse{Topic: req.Topic, Offsets: map[int32]int64{*req.Partition: offset}})
+}
+
+// ReplayConsumer godoc
+// @Summary Перечитать сообщения с момента времени
+// @Description Перематывает партиции на первое сообщение не раньше timestamp (RFC 3339)
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.ConsumerReplayRequest true "Топик, партиции и время"
+// @Success 200 {object} handler.ConsumerSeekResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/consumer/replay [post]
+func (h *ConsumerHandler) ReplayConsumer(c *gin.Context) {
+	var req ConsumerReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+		return
+	}
+	offsets, err := h.control.ReplayFrom(req.Topic, req.Partitions, req.Timestamp)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ConsumerSeekResponse{Topic: req.Topic, Offsets: offsets})
+}
+
+func (h *ConsumerHandler) fail(c *gin.Context, err error) {
+	if errors.Is(err, kafka.ErrPartitionNotAssigned) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.logger.Error("Consumer control failed", slog.String("error", err.Error()))
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+}
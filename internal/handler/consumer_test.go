@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	mock_handler "l0/internal/handler/mocks"
+	"l0/internal/kafka"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupConsumerRouter(mockControl *mock_handler.MockConsumerControl) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewConsumerHandler(slog.Default(), mockControl)
+	r := gin.New()
+	r.GET("/admin/consumer", h.ConsumerState)
+	r.POST("/admin/consumer/pause", h.PauseConsumer)
+	r.POST("/admin/consumer/seek", h.SeekConsumer)
+	r.POST("/admin/consumer/replay", h.ReplayConsumer)
+	return r
+}
+
+func TestConsumerHandler_Pause(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_handler.NewMockConsumerControl(ctrl)
+	mockControl.EXPECT().Pause("orders", []int32{1}).Return(nil)
+	mockControl.EXPECT().Pause("orders", []int32{7}).
+		Return(fmt.Errorf("partition 7: %w", kafka.ErrPartitionNotAssigned))
+	mockControl.EXPECT().Partitions().Return([]kafka.PartitionState{
+		{Topic: "orders", Partition: 1, State: kafka.PartitionPaused},
+	})
+
+	r := setupConsumerRouter(mockControl)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/pause",
+		strings.NewReader(`{"topic":"orders","partitions":[1]}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"state":"paused"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/pause",
+		strings.NewReader(`{"topic":"orders","partitions":[7]}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestConsumerHandler_SeekAndReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	mockControl := mock_handler.NewMockConsumerControl(ctrl)
+	mockControl.EXPECT().Seek("orders", int32(0), int64(-2)).Return(int64(15), nil)
+	mockControl.EXPECT().ReplayFrom("orders", nil, at).Return(map[int32]int64{0: 40, 1: 42}, nil)
+
+	r := setupConsumerRouter(mockControl)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/seek",
+		strings.NewReader(`{"topic":"orders","partition":0,"offset":-2}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"0":15`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/seek",
+		strings.NewReader(`{"topic":"orders","offset":5}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/replay",
+		strings.NewReader(`{"topic":"orders","timestamp":"2025-09-01T12:00:00Z"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"1":42`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/replay",
+		strings.NewReader(`{"topic":"orders","timestamp":"yesterday"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
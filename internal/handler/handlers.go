@@ -2,7 +2,9 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"l0/internal/cache"
 	"l0/internal/domain"
 	"l0/internal/service"
 	"l0/pkg/e"
@@ -23,8 +25,17 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// ValidationErrorResponse заказ не прошёл проверку
+type ValidationErrorResponse struct {
+	Error      string             `json:"error"`
+	Violations []domain.Violation `json:"violations"`
+}
+
 // @title OrderService App Api
 // @version 1
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name X-Admin-Token
 
 type OrderRepository interface {
 	GetByID(ctx context.Context, id int) (domain.Order, error)
@@ -35,19 +46,37 @@ type Renderer interface {
 	RenderHome(http.ResponseWriter)
 }
 
+// ResponseCache кэш готовых ответов GET /orders/{id}
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (cache.Rendered, error)
+	Set(ctx context.Context, key string, value cache.Rendered, opts ...cache.SetOption) error
+}
+
+// OrderRules настраиваемые правила по Entry
+type OrderRules interface {
+	Check(order domain.Order) error
+}
+
 type Handler struct {
-	orderRepo OrderRepository
-	cacheRepo service.Cache
-	renderer  Renderer
-	logger    *slog.Logger
+	orderRepo     OrderRepository
+	cacheRepo     service.Cache
+	responseCache ResponseCache
+	orderRules    OrderRules
+	renderer      Renderer
+	logger        *slog.Logger
 }
 
-func NewHandler(logger *slog.Logger, orderService service.OrderRepository, cacheService service.Cache, serviceRender Renderer) *Handler {
+// NewHandler создаёт обработчики API. responseCache может быть nil — тогда
+// ответы каждый раз сериализуются заново; orderRules может быть nil — тогда
+// проверяются только встроенные правила.
+func NewHandler(logger *slog.Logger, orderService OrderRepository, cacheService service.Cache, responseCache ResponseCache, serviceRender Renderer, orderRules OrderRules) *Handler {
 	return &Handler{
-		orderRepo: orderService,
-		cacheRepo: cacheService,
-		logger:    logger,
-		renderer:  serviceRender,
+		orderRepo:     orderService,
+		cacheRepo:     cacheService,
+		responseCache: responseCache,
+		orderRules:    orderRules,
+		logger:        logger,
+		renderer:      serviceRender,
 	}
 }
 
@@ -55,7 +84,10 @@ func NewHandler(logger *slog.Logger, orderService service.OrderRepository, cache
 // @Summary Получить заказ по ID
 // @Description Возвращает заказ по уникальному идентификатору
 // @Param id path int true "ID заказа"
+// @Param If-None-Match header string false "ETag ранее полученного ответа"
 // @Success 200 {object} handler.OrderResponse
+// @Header 200 {string} ETag "Хэш тела ответа"
+// @Success 304 "Заказ не изменился"
 // @Failure 400 {object} handler.ErrorResponse
 // @Failure 404 {object} handler.ErrorResponse
 // @Failure 500 {object} handler.ErrorResponse
@@ -69,6 +101,15 @@ func (h *Handler) GetOrderByID(c *gin.Context) {
 		return
 	}
 
+	// быстрый путь: готовое тело ответа из кэша без декодирования заказа
+	key := cache.OrderResponseKey(id)
+	if h.responseCache != nil {
+		if rendered, err := h.responseCache.Get(c.Request.Context(), key); err == nil {
+			writeRendered(c, rendered)
+			return
+		}
+	}
+
 	order, err := h.orderRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
 		if errors.Is(err, e.ErrNotFound) {
@@ -81,7 +122,30 @@ func (h *Handler) GetOrderByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, OrderResponse{Order: order})
+	body, err := json.Marshal(OrderResponse{Order: order})
+	if err != nil {
+		h.logger.Error("Failed to marshal order", slog.Int("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+	rendered := cache.NewRendered(body)
+	if h.responseCache != nil {
+		if err := h.responseCache.Set(c.Request.Context(), key, rendered); err != nil {
+			h.logger.Debug("Failed to cache rendered order", slog.Int("id", id), slog.String("error", err.Error()))
+		}
+	}
+
+	writeRendered(c, rendered)
+}
+
+// writeRendered отдаёт готовое тело ответа, отвечая 304 при совпадении ETag
+func writeRendered(c *gin.Context, rendered cache.Rendered) {
+	c.Header("ETag", rendered.ETag)
+	if match := c.GetHeader("If-None-Match"); match != "" && match == rendered.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", rendered.Body)
 }
 
 // ShowHomepage отображает домашнюю страницу
@@ -97,6 +161,7 @@ func (h *Handler) ShowHomepage(c *gin.Context) {
 // @Param order body domain.Order true "Данные заказа"
 // @Success 201 {object} map[string]int "ID созданного заказа"
 // @Failure 400 {object} handler.ErrorResponse
+// @Failure 422 {object} handler.ValidationErrorResponse "Заказ не согласован или нарушает правила Entry"
 // @Failure 500 {object} handler.ErrorResponse
 // @Router /order [post]
 func (h *Handler) CreateOrder(c *gin.Context) {
@@ -107,6 +172,20 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	var verr *domain.ValidationError
+	if err := domain.ValidateOrder(order); errors.As(err, &verr) {
+		h.logger.Warn("Order rejected by validation", slog.String("order_uid", order.OrderUID), slog.String("error", err.Error()))
+		c.JSON(http.StatusUnprocessableEntity, ValidationErrorResponse{Error: "Order validation failed", Violations: verr.Violations})
+		return
+	}
+	if h.orderRules != nil {
+		if err := h.orderRules.Check(order); errors.As(err, &verr) {
+			h.logger.Warn("Order rejected by rules", slog.String("order_uid", order.OrderUID), slog.String("error", err.Error()))
+			c.JSON(http.StatusUnprocessableEntity, ValidationErrorResponse{Error: "Order validation failed", Violations: verr.Violations})
+			return
+		}
+	}
+
 	id, err := h.orderRepo.Create(c.Request.Context(), order)
 	if err != nil {
 		h.logger.Error("Failed to create order", slog.String("error", err.Error()))
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"l0/internal/cache"
 	"l0/internal/domain"
 	mock_handler "l0/internal/handler/mocks"
 	mock_service "l0/internal/service/mocks"
@@ -19,7 +20,7 @@ import (
 
 func setupRouter(logger *slog.Logger, mockRepo *mock_handler.MockOrderRepository, mockCache *mock_service.MockCache, mockRenderer *mock_handler.MockRenderer) *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	h := NewHandler(logger, mockRepo, mockCache, mockRenderer)
+	h := NewHandler(logger, mockRepo, mockCache, nil, mockRenderer, nil)
 	r := gin.New()
 	r.GET("/orders/:id", h.GetOrderByID)
 	r.POST("/orders", h.CreateOrder)
@@ -51,6 +52,46 @@ func TestHandler_GetOrderByID_Success(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "\"order_uid\":\"abc123\"")
 }
 
+func TestHandler_GetOrderByID_ResponseCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_handler.NewMockOrderRepository(ctrl)
+	mockResponses := mock_handler.NewMockResponseCache(ctrl)
+	rendered := cache.NewRendered([]byte(`{"order":{"order_uid":"abc123"}}`))
+
+	mockResponses.EXPECT().Get(gomock.Any(), cache.OrderResponseKey(1)).Return(cache.Rendered{}, e.ErrCacheMiss)
+	mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(domain.Order{OrderUID: "abc123"}, nil)
+	mockResponses.EXPECT().Set(gomock.Any(), cache.OrderResponseKey(1), gomock.Any()).Return(nil)
+	mockResponses.EXPECT().Get(gomock.Any(), cache.OrderResponseKey(1)).Return(rendered, nil).Times(2)
+
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(slog.Default(), mockRepo, nil, mockResponses, nil, nil)
+	r := gin.New()
+	r.GET("/orders/:id", h.GetOrderByID)
+
+	// промах: заказ читается из репозитория, ответ сохраняется
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	// попадание: тело отдаётся как есть
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(rendered.Body), w.Body.String())
+	assert.Equal(t, rendered.ETag, w.Header().Get("ETag"))
+
+	// совпадение ETag
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set("If-None-Match", rendered.ETag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
 func TestHandler_GetOrderByID_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -82,7 +123,7 @@ func TestHandler_CreateOrder_Success(t *testing.T) {
 	mockRenderer := mock_handler.NewMockRenderer(ctrl)
 	logger := slog.Default()
 
-	orderJSON := `{"order_uid": "abc123"}`
+	orderJSON := `{"order_uid": "abc123", "payment": {"transaction": "abc123"}}`
 
 	mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
 
@@ -119,6 +160,26 @@ func TestHandler_CreateOrder_BindError(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Invalid input")
 }
 
+func TestHandler_CreateOrder_ValidationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := setupRouter(slog.Default(), mock_handler.NewMockOrderRepository(ctrl), mock_service.NewMockCache(ctrl), mock_handler.NewMockRenderer(ctrl))
+
+	orderJSON := `{"order_uid": "abc123", "payment": {"transaction": "abc123", "amount": 500, "goods_total": 300, "delivery_cost": 100},
+		"items": [{"price": 400, "sale": 25, "total_price": 300}]}`
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(orderJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"error": "Order validation failed", "violations": [
+		{"field": "Payment.Amount", "rule": "amount", "message": "amount must equal goods_total + delivery_cost + custom_fee", "expected": 400, "actual": 500}
+	]}`, w.Body.String())
+}
+
 func TestHandler_ShowHomepage(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"l0/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthChecker сводная проверка состояния компонентов
+type HealthChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+// Health godoc
+// @Summary Состояние сервиса
+// @Description Возвращает состояние компонентов. 503, если хотя бы один компонент недоступен
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /health [get]
+func Health(checker HealthChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Check(c.Request.Context())
+		code := http.StatusOK
+		if report.Status == health.StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, report)
+	}
+}
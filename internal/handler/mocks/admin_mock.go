@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	cache "l0/internal/cache"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCacheStatsProvider is a mock of CacheStatsProvider interface.
+type MockCacheStatsProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheStatsProviderMockRecorder
+}
+
+// MockCacheStatsProviderMockRecorder is the mock recorder for MockCacheStatsProvider.
+type MockCacheStatsProviderMockRecorder struct {
+	mock *MockCacheStatsProvider
+}
+
+// NewMockCacheStatsProvider creates a new mock instance.
+func NewMockCacheStatsProvider(ctrl *gomock.Controller) *MockCacheStatsProvider {
+	mock := &MockCacheStatsProvider{ctrl: ctrl}
+	mock.recorder = &MockCacheStatsProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheStatsProvider) EXPECT() *MockCacheStatsProviderMockRecorder {
+	return m.recorder
+}
+
+// CacheStats mocks base method.
+func (m *MockCacheStatsProvider) CacheStats() []cache.LayerStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStats")
+	ret0, _ := ret[0].([]cache.LayerStats)
+	return ret0
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockCacheStatsProviderMockRecorder) CacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockCacheStatsProvider)(nil).CacheStats))
+}
+
+// MockCacheAdmin is a mock of CacheAdmin interface.
+type MockCacheAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheAdminMockRecorder
+}
+
+// MockCacheAdminMockRecorder is the mock recorder for MockCacheAdmin.
+type MockCacheAdminMockRecorder struct {
+	mock *MockCacheAdmin
+}
+
+// NewMockCacheAdmin creates a new mock instance.
+func NewMockCacheAdmin(ctrl *gomock.Controller) *MockCacheAdmin {
+	mock := &MockCacheAdmin{ctrl: ctrl}
+	mock.recorder = &MockCacheAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheAdmin) EXPECT() *MockCacheAdminMockRecorder {
+	return m.recorder
+}
+
+// CacheStats mocks base method.
+func (m *MockCacheAdmin) CacheStats() []cache.LayerStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStats")
+	ret0, _ := ret[0].([]cache.LayerStats)
+	return ret0
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockCacheAdminMockRecorder) CacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockCacheAdmin)(nil).CacheStats))
+}
+
+// Evict mocks base method.
+func (m *MockCacheAdmin) Evict(ctx context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evict", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Evict indicates an expected call of Evict.
+func (mr *MockCacheAdminMockRecorder) Evict(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evict", reflect.TypeOf((*MockCacheAdmin)(nil).Evict), ctx, key)
+}
+
+// Flush mocks base method.
+func (m *MockCacheAdmin) Flush(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockCacheAdminMockRecorder) Flush(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockCacheAdmin)(nil).Flush), ctx)
+}
+
+// Inspect mocks base method.
+func (m *MockCacheAdmin) Inspect(ctx context.Context, key string) (cache.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Inspect", ctx, key)
+	ret0, _ := ret[0].(cache.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Inspect indicates an expected call of Inspect.
+func (mr *MockCacheAdminMockRecorder) Inspect(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockCacheAdmin)(nil).Inspect), ctx, key)
+}
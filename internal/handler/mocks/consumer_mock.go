@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: consumer.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	kafka "l0/internal/kafka"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockConsumerControl is a mock of ConsumerControl interface.
+type MockConsumerControl struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsumerControlMockRecorder
+}
+
+// MockConsumerControlMockRecorder is the mock recorder for MockConsumerControl.
+type MockConsumerControlMockRecorder struct {
+	mock *MockConsumerControl
+}
+
+// NewMockConsumerControl creates a new mock instance.
+func NewMockConsumerControl(ctrl *gomock.Controller) *MockConsumerControl {
+	mock := &MockConsumerControl{ctrl: ctrl}
+	mock.recorder = &MockConsumerControlMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsumerControl) EXPECT() *MockConsumerControlMockRecorder {
+	return m.recorder
+}
+
+// Partitions mocks base method.
+func (m *MockConsumerControl) Partitions() []kafka.PartitionState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Partitions")
+	ret0, _ := ret[0].([]kafka.PartitionState)
+	return ret0
+}
+
+// Partitions indicates an expected call of Partitions.
+func (mr *MockConsumerControlMockRecorder) Partitions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Partitions", reflect.TypeOf((*MockConsumerControl)(nil).Partitions))
+}
+
+// Pause mocks base method.
+func (m *MockConsumerControl) Pause(topic string, partitions []int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", topic, partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockConsumerControlMockRecorder) Pause(topic, partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockConsumerControl)(nil).Pause), topic, partitions)
+}
+
+// ReplayFrom mocks base method.
+func (m *MockConsumerControl) ReplayFrom(topic string, partitions []int32, at time.Time) (map[int32]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayFrom", topic, partitions, at)
+	ret0, _ := ret[0].(map[int32]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayFrom indicates an expected call of ReplayFrom.
+func (mr *MockConsumerControlMockRecorder) ReplayFrom(topic, partitions, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayFrom", reflect.TypeOf((*MockConsumerControl)(nil).ReplayFrom), topic, partitions, at)
+}
+
+// Resume mocks base method.
+func (m *MockConsumerControl) Resume(topic string, partitions []int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", topic, partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockConsumerControlMockRecorder) Resume(topic, partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockConsumerControl)(nil).Resume), topic, partitions)
+}
+
+// Seek mocks base method.
+func (m *MockConsumerControl) Seek(topic string, partition int32, offset int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seek", topic, partition, offset)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seek indicates an expected call of Seek.
+func (mr *MockConsumerControlMockRecorder) Seek(topic, partition, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seek", reflect.TypeOf((*MockConsumerControl)(nil).Seek), topic, partition, offset)
+}
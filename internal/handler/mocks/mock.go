@@ -6,6 +6,7 @@ package mock_handler
 
 import (
 	context "context"
+	cache "l0/internal/cache"
 	domain "l0/internal/domain"
 	http "net/http"
 	reflect "reflect"
@@ -100,3 +101,60 @@ func (mr *MockRendererMockRecorder) RenderHome(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderHome", reflect.TypeOf((*MockRenderer)(nil).RenderHome), arg0)
 }
+
+// MockResponseCache is a mock of ResponseCache interface.
+type MockResponseCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockResponseCacheMockRecorder
+}
+
+// MockResponseCacheMockRecorder is the mock recorder for MockResponseCache.
+type MockResponseCacheMockRecorder struct {
+	mock *MockResponseCache
+}
+
+// NewMockResponseCache creates a new mock instance.
+func NewMockResponseCache(ctrl *gomock.Controller) *MockResponseCache {
+	mock := &MockResponseCache{ctrl: ctrl}
+	mock.recorder = &MockResponseCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResponseCache) EXPECT() *MockResponseCacheMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockResponseCache) Get(ctx context.Context, key string) (cache.Rendered, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(cache.Rendered)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockResponseCacheMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockResponseCache)(nil).Get), ctx, key)
+}
+
+// Set mocks base method.
+func (m *MockResponseCache) Set(ctx context.Context, key string, value cache.Rendered, opts ...cache.SetOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key, value}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Set", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockResponseCacheMockRecorder) Set(ctx, key, value interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key, value}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockResponseCache)(nil).Set), varargs...)
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: quarantine.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	domain "l0/internal/domain"
+	service "l0/internal/service"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockQuarantineService is a mock of QuarantineService interface.
+type MockQuarantineService struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuarantineServiceMockRecorder
+}
+
+// MockQuarantineServiceMockRecorder is the mock recorder for MockQuarantineService.
+type MockQuarantineServiceMockRecorder struct {
+	mock *MockQuarantineService
+}
+
+// NewMockQuarantineService creates a new mock instance.
+func NewMockQuarantineService(ctrl *gomock.Controller) *MockQuarantineService {
+	mock := &MockQuarantineService{ctrl: ctrl}
+	mock.recorder = &MockQuarantineServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuarantineService) EXPECT() *MockQuarantineServiceMockRecorder {
+	return m.recorder
+}
+
+// Discard mocks base method.
+func (m *MockQuarantineService) Discard(ctx context.Context, id int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discard", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockQuarantineServiceMockRecorder) Discard(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockQuarantineService)(nil).Discard), ctx, id, reason)
+}
+
+// EditPayload mocks base method.
+func (m *MockQuarantineService) EditPayload(ctx context.Context, id int64, payload []byte) (domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditPayload", ctx, id, payload)
+	ret0, _ := ret[0].(domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditPayload indicates an expected call of EditPayload.
+func (mr *MockQuarantineServiceMockRecorder) EditPayload(ctx, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditPayload", reflect.TypeOf((*MockQuarantineService)(nil).EditPayload), ctx, id, payload)
+}
+
+// Get mocks base method.
+func (m *MockQuarantineService) Get(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockQuarantineServiceMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockQuarantineService)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockQuarantineService) List(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, f)
+	ret0, _ := ret[0].([]domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockQuarantineServiceMockRecorder) List(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockQuarantineService)(nil).List), ctx, f)
+}
+
+// Replay mocks base method.
+func (m *MockQuarantineService) Replay(ctx context.Context, id int64) (service.ReplayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, id)
+	ret0, _ := ret[0].(service.ReplayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockQuarantineServiceMockRecorder) Replay(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockQuarantineService)(nil).Replay), ctx, id)
+}
+
+// ReplayMany mocks base method.
+func (m *MockQuarantineService) ReplayMany(ctx context.Context, ids []int64) []service.ReplayResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayMany", ctx, ids)
+	ret0, _ := ret[0].([]service.ReplayResult)
+	return ret0
+}
+
+// ReplayMany indicates an expected call of ReplayMany.
+func (mr *MockQuarantineServiceMockRecorder) ReplayMany(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayMany", reflect.TypeOf((*MockQuarantineService)(nil).ReplayMany), ctx, ids)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rules.go
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	domain "l0/internal/domain"
+	rules "l0/internal/rules"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRulesEngine is a mock of RulesEngine interface.
+type MockRulesEngine struct {
+	ctrl     *gomock.Controller
+	recorder *MockRulesEngineMockRecorder
+}
+
+// MockRulesEngineMockRecorder is the mock recorder for MockRulesEngine.
+type MockRulesEngineMockRecorder struct {
+	mock *MockRulesEngine
+}
+
+// NewMockRulesEngine creates a new mock instance.
+func NewMockRulesEngine(ctrl *gomock.Controller) *MockRulesEngine {
+	mock := &MockRulesEngine{ctrl: ctrl}
+	mock.recorder = &MockRulesEngineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRulesEngine) EXPECT() *MockRulesEngineMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockRulesEngine) Check(order domain.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockRulesEngineMockRecorder) Check(order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockRulesEngine)(nil).Check), order)
+}
+
+// DryRun mocks base method.
+func (m *MockRulesEngine) DryRun(order domain.Order, src []byte) (rules.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRun", order, src)
+	ret0, _ := ret[0].(rules.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRun indicates an expected call of DryRun.
+func (mr *MockRulesEngineMockRecorder) DryRun(order, src interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRun", reflect.TypeOf((*MockRulesEngine)(nil).DryRun), order, src)
+}
+
+// Status mocks base method.
+func (m *MockRulesEngine) Status() rules.Status {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(rules.Status)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockRulesEngineMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockRulesEngine)(nil).Status))
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"l0/internal/domain"
+	"l0/internal/service"
+	"l0/pkg/e"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=quarantine.go -destination=mocks/quarantine_mock.go
+
+// QuarantineService операции с карантином, реализуется service.Quarantine
+type QuarantineService interface {
+	List(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error)
+	Get(ctx context.Context, id int64) (domain.QuarantinedMessage, error)
+	EditPayload(ctx context.Context, id int64, payload []byte) (domain.QuarantinedMessage, error)
+	Replay(ctx context.Context, id int64) (service.ReplayResult, error)
+	ReplayMany(ctx context.Context, ids []int64) []service.ReplayResult
+	Discard(ctx context.Context, id int64, reason string) error
+}
+
+// Обертка для swagger ответа со списком карантина
+type QuarantineListResponse struct {
+	Messages []domain.QuarantinedMessage `json:"messages"`
+}
+
+// Запрос на повторную обработку нескольких сообщений
+type QuarantineReplayRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1"`
+}
+
+// Обертка для swagger ответа о повторной обработке
+type QuarantineReplayResponse struct {
+	Results []service.ReplayResult `json:"results"`
+}
+
+// Запрос на отбрасывание сообщения
+type QuarantineDiscardRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type QuarantineHandler struct {
+	quarantine QuarantineService
+	logger     *slog.Logger
+}
+
+func NewQuarantineHandler(logger *slog.Logger, quarantine QuarantineService) *QuarantineHandler {
+	return &QuarantineHandler{
+		quarantine: quarantine,
+		logger:     logger,
+	}
+}
+
+// ListQuarantined godoc
+// @Summary Список сообщений в карантине
+// @Description Возвращает необработанные сообщения, новые первыми
+// @Produce json
+// @Security AdminToken
+// @Param status query string false "Статус: pending, replaying, replayed, discarded"
+// @Param stage query string false "Этап ошибки: decode, validate, persist"
+// @Param topic query string false "Исходный топик"
+// @Param limit query int false "Размер страницы (по умолчанию 50)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} handler.QuarantineListResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/quarantine [get]
+func (h *QuarantineHandler) ListQuarantined(c *gin.Context) {
+	f := domain.QuarantineFilter{
+		Status: domain.QuarantineStatus(c.Query("status")),
+		Stage:  c.Query("stage"),
+		Topic:  c.Query("topic"),
+	}
+	var err error
+	if v := c.Query("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid offset"})
+			return
+		}
+	}
+
+	messages, err := h.quarantine.List(c.Request.Context(), f)
+	if err != nil {
+		h.fail(c, 0, "Failed to list quarantine", err)
+		return
+	}
+	c.JSON(http.StatusOK, QuarantineListResponse{Messages: messages})
+}
+
+// GetQuarantined godoc
+// @Summary Сообщение из карантина
+// @Description Возвращает исходное тело сообщения, причину ошибки и нарушения проверки
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "ID сообщения"
+// @Success 200 {object} domain.QuarantinedMessage
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 404 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/quarantine/{id} [get]
+func (h *QuarantineHandler) GetQuarantined(c *gin.Context) {
+	id, ok := h.messageID(c)
+	if !ok {
+		return
+	}
+
+	m, err := h.quarantine.Get(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, id, "Failed to get quarantined message", err)
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+// EditQuarantined godoc
+// @Summary Исправить тело сообщения
+// @Description Заменяет тело ожидающего сообщения; тело запроса сохраняется как есть
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "ID сообщения"
+// @Param payload body domain.Order true "Исправленный заказ"
+// @Success 200 {object} domain.QuarantinedMessage
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 404 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/quarantine/{id}/payload [put]
+func (h *QuarantineHandler) EditQuarantined(c *gin.Context) {
+	id, ok := h.messageID(c)
+	if !ok {
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil || len(payload) == 0 || !json.Valid(payload) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Payload must be valid JSON"})
+		return
+	}
+
+	m, err := h.quarantine.EditPayload(c.Request.Context(), id, payload)
+	if err != nil {
+		h.fail(c, id, "Failed to edit quarantined message", err)
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+// ReplayQuarantined godoc
+// @Summary Повторно обработать сообщение
+// @Description Пропускает сообщение через обычный путь обработки заказов. При неудаче сообщение остаётся в карантине, а ответ содержит причину.
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "ID сообщения"
+// @Success 200 {object} service.ReplayResult
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 404 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/quarantine/{id}/replay [post]
+func (h *QuarantineHandler) ReplayQuarantined(c *gin.Context) {
+	id, ok := h.messageID(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.quarantine.Replay(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, id, "Failed to replay quarantined message", err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ReplayQuarantinedMany godoc
+// @Summary Повторно обработать несколько сообщений
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.QuarantineReplayRequest true "ID сообщений"
+// @Success 200 {object} handler.QuarantineReplayResponse
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Router /admin/quarantine/replay [post]
+func (h *QuarantineHandler) ReplayQuarantinedMany(c *gin.Context) {
+	var req QuarantineReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	c.JSON(http.StatusOK, QuarantineReplayResponse{Results: h.quarantine.ReplayMany(c.Request.Context(), req.IDs)})
+}
+
+// DiscardQuarantined godoc
+// @Summary Отбросить сообщение
+// @Accept json
+// @Security AdminToken
+// @Param id path int true "ID сообщения"
+// @Param request body handler.QuarantineDiscardRequest true "Причина"
+// @Success 204
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Failure 404 {object} handler.ErrorResponse
+// @Failure 409 {object} handler.ErrorResponse
+// @Failure 500 {object} handler.ErrorResponse
+// @Router /admin/quarantine/{id}/discard [post]
+func (h *QuarantineHandler) DiscardQuarantined(c *gin.Context) {
+	id, ok := h.messageID(c)
+	if !ok {
+		return
+	}
+
+	var req QuarantineDiscardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Reason is required"})
+		return
+	}
+
+	if err := h.quarantine.Discard(c.Request.Context(), id, req.Reason); err != nil {
+		h.fail(c, id, "Failed to discard quarantined message", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *QuarantineHandler) messageID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid quarantine id", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid message ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// fail отвечает кодом, соответствующим ошибке сервиса
+func (h *QuarantineHandler) fail(c *gin.Context, id int64, msg string, err error) {
+	switch {
+	case errors.Is(err, e.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Message not found"})
+	case errors.Is(err, e.ErrQuarantineResolved):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Message is already replayed or discarded"})
+	default:
+		h.logger.Error(msg, slog.Int64("id", id), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+	}
+}
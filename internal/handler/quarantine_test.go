@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"l0/internal/domain"
+	mock_handler "l0/internal/handler/mocks"
+	"l0/internal/service"
+	"l0/pkg/e"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupQuarantineRouter(mockQuarantine *mock_handler.MockQuarantineService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewQuarantineHandler(slog.Default(), mockQuarantine)
+	r := gin.New()
+	r.GET("/admin/quarantine", h.ListQuarantined)
+	r.POST("/admin/quarantine/replay", h.ReplayQuarantinedMany)
+	r.PUT("/admin/quarantine/:id/payload", h.EditQuarantined)
+	r.POST("/admin/quarantine/:id/replay", h.ReplayQuarantined)
+	r.POST("/admin/quarantine/:id/discard", h.DiscardQuarantined)
+	return r
+}
+
+func TestQuarantineHandler_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuarantine := mock_handler.NewMockQuarantineService(ctrl)
+	mockQuarantine.EXPECT().List(gomock.Any(), domain.QuarantineFilter{
+		Status: domain.QuarantinePending, Stage: "validate", Limit: 10,
+	}).Return([]domain.QuarantinedMessage{{ID: 3, Stage: "validate"}}, nil)
+
+	w := httptest.NewRecorder()
+	setupQuarantineRouter(mockQuarantine).ServeHTTP(w,
+		httptest.NewRequest(http.MethodGet, "/admin/quarantine?status=pending&stage=validate&limit=10", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":3`)
+}
+
+func TestQuarantineHandler_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuarantine := mock_handler.NewMockQuarantineService(ctrl)
+	mockQuarantine.EXPECT().Replay(gomock.Any(), int64(5)).Return(service.ReplayResult{
+		ID: 5, Status: domain.QuarantinePending, Stage: "validate",
+		Violations: []domain.Violation{{Field: "delivery.phone", Rule: "e164"}},
+	}, nil)
+	mockQuarantine.EXPECT().Replay(gomock.Any(), int64(6)).Return(service.ReplayResult{}, e.ErrQuarantineResolved)
+
+	r := setupQuarantineRouter(mockQuarantine)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/quarantine/5/replay", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"delivery.phone"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/quarantine/6/replay", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestQuarantineHandler_EditAndDiscardValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuarantine := mock_handler.NewMockQuarantineService(ctrl)
+	r := setupQuarantineRouter(mockQuarantine)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/quarantine/5/payload", strings.NewReader("{broken")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/quarantine/5/discard", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockQuarantine.EXPECT().Discard(gomock.Any(), int64(5), "duplicate").Return(nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/quarantine/5/discard", strings.NewReader(`{"reason":"duplicate"}`)))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
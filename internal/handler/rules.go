@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"l0/internal/domain"
+	"l0/internal/rules"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=rules.go -destination=mocks/rules_mock.go
+
+// RulesEngine правила проверки заказов по Entry, реализуется rules.Engine
+type RulesEngine interface {
+	Check(order domain.Order) error
+	Status() rules.Status
+	DryRun(order domain.Order, src []byte) (rules.Report, error)
+}
+
+// Запрос на пробную проверку заказа правилами
+type RulesDryRunRequest struct {
+	Order domain.Order `json:"order"`
+	// YAML с правилами; пустой — проверка действующими правилами
+	Rules string `json:"rules"`
+}
+
+type RulesHandler struct {
+	engine RulesEngine
+	logger *slog.Logger
+}
+
+func NewRulesHandler(logger *slog.Logger, engine RulesEngine) *RulesHandler {
+	return &RulesHandler{
+		engine: engine,
+		logger: logger,
+	}
+}
+
+// GetRules godoc
+// @Summary Действующие правила проверки заказов
+// @Description Правила из RULES_FILE и ошибка последней перезагрузки файла, если она была
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} rules.Status
+// @Failure 401 {object} handler.ErrorResponse
+// @Router /admin/rules [get]
+func (h *RulesHandler) GetRules(c *gin.Context) {
+	c.JSON(http.StatusOK, h.engine.Status())
+}
+
+// DryRunRules godoc
+// @Summary Пробная проверка заказа правилами
+// @Description Проверяет заказ правилами из запроса или действующими, ничего не сохраняя. Встроенные проверки заказа не выполняются.
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body handler.RulesDryRunRequest true "Заказ и правила"
+// @Success 200 {object} rules.Report
+// @Failure 400 {object} handler.ErrorResponse
+// @Failure 401 {object} handler.ErrorResponse
+// @Router /admin/rules/dry-run [post]
+func (h *RulesHandler) DryRunRules(c *gin.Context) {
+	var req RulesDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+		return
+	}
+	var src []byte
+	if req.Rules != "" {
+		src = []byte(req.Rules)
+	}
+	report, err := h.engine.DryRun(req.Order, src)
+	if err != nil {
+		// ошибка разбора правил нужна автору правил целиком
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
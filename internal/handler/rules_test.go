@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"l0/internal/domain"
+	mock_handler "l0/internal/handler/mocks"
+	"l0/internal/rules"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesHandler_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mock_handler.NewMockRulesEngine(ctrl)
+	violation := domain.Violation{Field: "Payment.Currency", Rule: "wbil-currency", Message: "currency must be USD or EUR", Actual: "RUB"}
+	mockEngine.EXPECT().DryRun(gomock.Any(), []byte(nil)).DoAndReturn(func(o domain.Order, _ []byte) (rules.Report, error) {
+		assert.Equal(t, "WBIL", o.Entry)
+		return rules.Report{
+			Results:    []rules.Result{{Rule: "wbil-currency", Applied: true, Violation: &violation}},
+			Violations: []domain.Violation{violation},
+		}, nil
+	})
+	mockEngine.EXPECT().DryRun(gomock.Any(), []byte("rules: [")).Return(rules.Report{}, errors.New("parse rules: yaml: line 1"))
+
+	gin.SetMode(gin.TestMode)
+	h := NewRulesHandler(slog.Default(), mockEngine)
+	r := gin.New()
+	r.POST("/admin/rules/dry-run", h.DryRunRules)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/rules/dry-run",
+		strings.NewReader(`{"order":{"entry":"WBIL","payment":{"currency":"RUB"}}}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"accepted":false`)
+	assert.Contains(t, w.Body.String(), `"rule":"wbil-currency"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/rules/dry-run",
+		strings.NewReader(`{"order":{"entry":"WBIL"},"rules":"rules: ["}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "parse rules")
+}
+
+func TestHandler_CreateOrder_RejectedByRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_handler.NewMockOrderRepository(ctrl)
+	mockEngine := mock_handler.NewMockRulesEngine(ctrl)
+	mockEngine.EXPECT().Check(gomock.Any()).Return(&domain.ValidationError{Violations: []domain.Violation{
+		{Rule: "max-items", Message: "order must contain at most 50 items"},
+	}})
+
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(slog.Default(), mockRepo, nil, nil, nil, mockEngine)
+	r := gin.New()
+	r.POST("/orders", h.CreateOrder)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"order_uid": "abc123", "payment": {"transaction": "abc123"}}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"rule":"max-items"`)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -23,10 +24,10 @@ type Server struct {
 	cfg    *config.Config
 }
 
-func NewServer(ctx context.Context, config *config.Config, logger *slog.Logger, orderService service.OrderRepository, cacheService service.Cache, serviceRender Renderer) *Server {
+func NewServer(ctx context.Context, config *config.Config, logger *slog.Logger, orderService OrderRepository, cacheService service.Cache, responseCache ResponseCache, cacheAdmin CacheAdmin, quarantine QuarantineService, consumer ConsumerControl, rulesEngine RulesEngine, healthChecker HealthChecker, serviceRender Renderer) *Server {
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", config.Http.Port),
-		Handler: InitRouter(ctx, logger, orderService, cacheService, serviceRender),
+		Handler: InitRouter(ctx, config, logger, orderService, cacheService, responseCache, cacheAdmin, quarantine, consumer, rulesEngine, healthChecker, serviceRender),
 	}
 
 	return &Server{
@@ -36,15 +37,23 @@ func NewServer(ctx context.Context, config *config.Config, logger *slog.Logger,
 	}
 }
 
-func InitRouter(ctx context.Context, logger *slog.Logger, orderService service.OrderRepository, cacheService service.Cache, serviceRender Renderer) *gin.Engine {
+func InitRouter(ctx context.Context, cfg *config.Config, logger *slog.Logger, orderService OrderRepository, cacheService service.Cache, responseCache ResponseCache, cacheAdmin CacheAdmin, quarantine QuarantineService, consumer ConsumerControl, rulesEngine RulesEngine, healthChecker HealthChecker, serviceRender Renderer) *gin.Engine {
 	r := gin.Default()
 
-	h := NewHandler(logger, orderService, cacheService, serviceRender)
+	h := NewHandler(logger, orderService, cacheService, responseCache, serviceRender, rulesEngine)
+	var extraStats []CacheStatsProvider
+	if p, ok := responseCache.(CacheStatsProvider); ok {
+		extraStats = append(extraStats, p)
+	}
+	admin := NewAdminHandler(logger, cacheAdmin, extraStats...)
+	qh := NewQuarantineHandler(logger, quarantine)
+	ch := NewConsumerHandler(logger, consumer)
+	rh := NewRulesHandler(logger, rulesEngine)
 	docsURL := ginSwagger.URL("http://localhost:8080/swagger/doc.json")
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:8080"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Admin-Token"}
 	config.AllowCredentials = true
 
 	r.Use(cors.New(config))
@@ -52,8 +61,34 @@ func InitRouter(ctx context.Context, logger *slog.Logger, orderService service.O
 	r.GET("/", h.ShowHomepage)
 	r.GET("/orders/:id", h.GetOrderByID)
 	r.POST("/order", h.CreateOrder)
+	r.GET("/health", Health(healthChecker))
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, docsURL))
 
+	// без токена административный API не подключается вовсе
+	if cfg.Http.AdminToken == "" {
+		logger.Warn("admin API is disabled, ADMIN_TOKEN is not set")
+		return r
+	}
+	adminGroup := r.Group("/admin", AdminAuth(cfg.Http.AdminToken))
+	adminGroup.GET("/cache/stats", admin.CacheStats)
+	adminGroup.GET("/cache/orders/:id", admin.InspectCachedOrder)
+	adminGroup.DELETE("/cache/orders/:id", admin.EvictCachedOrder)
+	adminGroup.DELETE("/cache/orders", admin.FlushCachedOrders)
+	adminGroup.GET("/quarantine", qh.ListQuarantined)
+	adminGroup.POST("/quarantine/replay", qh.ReplayQuarantinedMany)
+	adminGroup.GET("/quarantine/:id", qh.GetQuarantined)
+	adminGroup.PUT("/quarantine/:id/payload", qh.EditQuarantined)
+	adminGroup.POST("/quarantine/:id/replay", qh.ReplayQuarantined)
+	adminGroup.POST("/quarantine/:id/discard", qh.DiscardQuarantined)
+	adminGroup.GET("/consumer", ch.ConsumerState)
+	adminGroup.POST("/consumer/pause", ch.PauseConsumer)
+	adminGroup.POST("/consumer/resume", ch.ResumeConsumer)
+	adminGroup.POST("/consumer/seek", ch.SeekConsumer)
+	adminGroup.POST("/consumer/replay", ch.ReplayConsumer)
+	adminGroup.GET("/rules", rh.GetRules)
+	adminGroup.POST("/rules/dry-run", rh.DryRunRules)
+
 	return r
 }
 
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status состояние компонента
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// checkTimeout ограничивает время одной проверки
+const checkTimeout = 2 * time.Second
+
+// Result результат проверки одного компонента
+type Result struct {
+	Status  Status         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Checker проверяет состояние компонента
+type Checker interface {
+	Check(ctx context.Context) Result
+}
+
+// CheckerFunc позволяет использовать функцию как Checker
+type CheckerFunc func(ctx context.Context) Result
+
+func (f CheckerFunc) Check(ctx context.Context) Result {
+	return f(ctx)
+}
+
+// Report сводный результат проверок
+type Report struct {
+	Status     Status            `json:"status"`
+	Components map[string]Result `json:"components"`
+}
+
+// Registry набор проверок компонентов сервиса
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+func NewRegistry() *Registry {
+	return &Registry{checkers: make(map[string]Checker)}
+}
+
+// Register добавляет проверку компонента под именем name
+func (r *Registry) Register(name string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = c
+}
+
+// Check параллельно выполняет все проверки. Итоговый статус — худший из статусов компонентов.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := make(map[string]Checker, len(r.checkers))
+	for name, c := range r.checkers {
+		checkers[name] = c
+	}
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	report := Report{Status: StatusUp, Components: make(map[string]Result, len(checkers))}
+	for name, c := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := c.Check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[name] = res
+			report.Status = worst(report.Status, res.Status)
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusUp: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// consumeBatches копит до BatchSize сообщений партиции или ждёт не дольше
+// BatchLinger, после чего обрабатывает пакет и отмечает его смещения
+func (kc *KafkaConsumer) consumeBatches(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, handler MessageHandler) error {
+	ctx := sess.Context()
+	size := kc.cfg.Kafka.BatchSize
+	batch := make([]*sarama.ConsumerMessage, 0, size)
+
+	var timer *time.Timer
+	var linger <-chan time.Time
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, linger = nil, nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() { batch = batch[:0] }()
+
+		if err := kc.processBatch(ctx, handler, batch); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			kc.logger.Error("failed to process batch, restarting session",
+				"partition", claim.Partition(),
+				"first_offset", batch[0].Offset,
+				"size", len(batch),
+				"error", err.Error())
+			return kc.failSession(batch[0], err)
+		}
+		for _, msg := range batch {
+			sess.MarkMessage(msg, "")
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				kc.logger.Info("message channel closed", "partition", claim.Partition())
+				return flush()
+			}
+			batch = append(batch, msg)
+			if len(batch) == 1 {
+				timer = time.NewTimer(kc.cfg.Kafka.BatchLinger)
+				linger = timer.C
+			}
+			if len(batch) >= size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+		case <-linger:
+			if err := flush(); err != nil {
+				return err
+			}
+
+		case <-ctx.Done():
+			// неполный пакет не отмечен и будет прочитан повторно
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		}
+	}
+}
+
+// processBatch разбирает и проверяет сообщения пакета, применяет корректные
+// одной транзакцией и отправляет ошибочные сообщения в DLQ по одному.
+// Если пакетная транзакция не удалась или обработчик не умеет применять
+// пакеты, сообщения применяются по одному, чтобы в DLQ попали только
+// действительно проблемные. Ошибка означает, что смещения пакета отмечать
+// нельзя. Каждое сообщение отмечается в БД отдельно, поэтому сообщения,
+// ушедшие в DLQ или на повтор, отмечаются сразу, не дожидаясь пакета.
+func (kc *KafkaConsumer) processBatch(ctx context.Context, handler MessageHandler, batch []*sarama.ConsumerMessage) error {
+	batcher, ok := handler.(BatchHandler)
+	if !ok {
+		for _, msg := range batch {
+			if err := kc.processMessage(ctx, handler, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := time.Now()
+	decoded := make([]Message, 0, len(batch))
+	valid := make([]*sarama.ConsumerMessage, 0, len(batch))
+	for _, msg := range batch {
+		if kc.processed(msg) {
+			continue
+		}
+		m, failure := batcher.Decode(ctx, messageHeaders(msg), msg.Value)
+		if failure != nil {
+			if err := kc.routeFailure(ctx, msg, failure); err != nil {
+				return err
+			}
+			kc.metrics.observe(msg.Topic, start)
+			continue
+		}
+		decoded = append(decoded, m)
+		valid = append(valid, msg)
+	}
+	if len(decoded) == 0 {
+		return nil
+	}
+
+	_, err := kc.withRetries(ctx, valid[0], func() error {
+		return batcher.HandleBatch(kc.withOffset(ctx, valid...), decoded)
+	})
+	if err == nil {
+		kc.logger.Debug("batch stored", "partition", valid[0].Partition, "messages", len(decoded))
+		for _, msg := range valid {
+			kc.metrics.observe(msg.Topic, start)
+		}
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	kc.logger.Warn("batch transaction failed, handling messages one by one",
+		"partition", valid[0].Partition,
+		"messages", len(decoded),
+		"error", err.Error())
+	for _, msg := range valid {
+		if err := kc.processMessage(ctx, handler, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
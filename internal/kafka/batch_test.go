@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB отклоняет пакеты и заказы с OrderUID из reject
+type fakeDB struct {
+	mu      sync.Mutex
+	reject  map[string]bool
+	batches int
+	stored  []string
+}
+
+func (db *fakeDB) CreateOrder(_ context.Context, order domain.Order) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.reject[order.OrderUID] {
+		return 0, errors.New("constraint violation")
+	}
+	db.stored = append(db.stored, order.OrderUID)
+	return len(db.stored), nil
+}
+
+func (db *fakeDB) CreateOrders(_ context.Context, orders []domain.Order) ([]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.batches++
+	for _, o := range orders {
+		if db.reject[o.OrderUID] {
+			return nil, errors.New("constraint violation")
+		}
+	}
+	ids := make([]int, 0, len(orders))
+	for _, o := range orders {
+		db.stored = append(db.stored, o.OrderUID)
+		ids = append(ids, len(db.stored))
+	}
+	return ids, nil
+}
+
+// orderHandlers обработчик заказов для топика orders
+func orderHandlers(db DB) *Handlers {
+	h := NewHandlers()
+	h.Register("orders", NewOrderHandler(NewPipeline(db, nil, nil)))
+	return h
+}
+
+func ordersHandler(kc *KafkaConsumer) MessageHandler {
+	h, _ := kc.handlers.Handler("orders")
+	return h
+}
+
+func orderMessage(t *testing.T, uid string, offset int64) *sarama.ConsumerMessage {
+	t.Helper()
+	model, err := os.ReadFile("../../model.json")
+	require.NoError(t, err)
+	value := strings.ReplaceAll(string(model), "b563feb7b2b84b6test", uid)
+	return &sarama.ConsumerMessage{Value: []byte(value), Offset: offset}
+}
+
+func TestKafkaConsumer_ProcessBatch(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{Kafka: config.KafkaConfig{BatchSize: 10}}
+
+	t.Run("valid orders in one transaction", func(t *testing.T) {
+		db := &fakeDB{}
+		dlq := &recordingDLQ{}
+		kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), dlq, nil, nil)
+
+		err := kc.processBatch(ctx, ordersHandler(kc), []*sarama.ConsumerMessage{
+			orderMessage(t, "a", 1),
+			{Value: []byte("not json"), Offset: 2},
+			orderMessage(t, "b", 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, db.batches)
+		assert.Equal(t, []string{"a", "b"}, db.stored)
+		require.Len(t, dlq.failures, 1)
+		assert.Equal(t, StageDecode, dlq.failures[0].Stage)
+	})
+
+	t.Run("failed transaction falls back to single inserts", func(t *testing.T) {
+		db := &fakeDB{reject: map[string]bool{"b": true}}
+		dlq := &recordingDLQ{}
+		kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), dlq, nil, nil)
+
+		err := kc.processBatch(ctx, ordersHandler(kc), []*sarama.ConsumerMessage{
+			orderMessage(t, "a", 1),
+			orderMessage(t, "b", 2),
+			orderMessage(t, "c", 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, db.stored)
+		require.Len(t, dlq.failures, 1)
+		assert.Equal(t, StagePersist, dlq.failures[0].Stage)
+	})
+}
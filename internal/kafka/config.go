@@ -0,0 +1,200 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"l0/internal/config"
+	"os"
+
+	"github.com/IBM/sarama"
+)
+
+// NewClientConfig настройки sarama с подключением к брокерам: версия
+// протокола, client id, TLS, SASL, размеры чтения и тайм-ауты сессии
+func NewClientConfig(cfg config.KafkaClientConfig) (*sarama.Config, error) {
+	sc := sarama.NewConfig()
+	if cfg.ClientID != "" {
+		sc.ClientID = cfg.ClientID
+	}
+	if cfg.Version != "" {
+		version, err := sarama.ParseKafkaVersion(cfg.Version)
+		if err != nil {
+			return nil, fmt.Errorf("kafka version: %w", err)
+		}
+		sc.Version = version
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		sc.Net.TLS.Enable = true
+		sc.Net.TLS.Config = tlsConfig
+	}
+
+	if cfg.SASLMechanism != "" {
+		sc.Net.SASL.Enable = true
+		sc.Net.SASL.Mechanism = sarama.SASLMechanism(cfg.SASLMechanism)
+		sc.Net.SASL.User = cfg.SASLUsername
+		sc.Net.SASL.Password = cfg.SASLPassword
+		switch cfg.SASLMechanism {
+		case sarama.SASLTypeSCRAMSHA256:
+			sc.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: scramSHA256} }
+		case sarama.SASLTypeSCRAMSHA512:
+			sc.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: scramSHA512} }
+		}
+	}
+
+	if cfg.DialTimeout > 0 {
+		sc.Net.DialTimeout = cfg.DialTimeout
+	}
+	if cfg.FetchMinBytes > 0 {
+		sc.Consumer.Fetch.Min = int32(cfg.FetchMinBytes)
+	}
+	if cfg.FetchDefaultBytes > 0 {
+		sc.Consumer.Fetch.Default = int32(cfg.FetchDefaultBytes)
+	}
+	if cfg.FetchMaxBytes > 0 {
+		sc.Consumer.Fetch.Max = int32(cfg.FetchMaxBytes)
+	}
+	if cfg.SessionTimeout > 0 {
+		sc.Consumer.Group.Session.Timeout = cfg.SessionTimeout
+	}
+	if cfg.HeartbeatInterval > 0 {
+		sc.Consumer.Group.Heartbeat.Interval = cfg.HeartbeatInterval
+	}
+	return sc, nil
+}
+
+// newTLSConfig собирает tls.Config из файлов CA и клиентского сертификата
+func newTLSConfig(cfg config.KafkaClientConfig) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// validated проверяет итоговые настройки sarama
+func validated(sc *sarama.Config) (*sarama.Config, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("kafka config: %w", err)
+	}
+	return sc, nil
+}
+
+// NewSaramaConfig собирает настройки клиента sarama для группы потребителей
+func NewSaramaConfig(cfg config.KafkaConfig) (*sarama.Config, error) {
+	sc, err := NewClientConfig(cfg.KafkaClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	sc.Consumer.Return.Errors = true
+
+	sc.Consumer.Offsets.Initial = sarama.OffsetOldest
+	if cfg.InitialOffset == "newest" {
+		sc.Consumer.Offsets.Initial = sarama.OffsetNewest
+	}
+	// смещения фиксируются только для сообщений, отмеченных после сохранения заказа
+	sc.Consumer.Offsets.AutoCommit.Enable = true
+	if cfg.CommitInterval > 0 {
+		sc.Consumer.Offsets.AutoCommit.Interval = cfg.CommitInterval
+	}
+
+	switch cfg.RebalanceStrategy {
+	case "roundrobin":
+		sc.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+	case "sticky":
+		sc.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+	default:
+		sc.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRange()}
+	}
+
+	return validated(sc)
+}
+
+// NewProducerConfig собирает настройки синхронного продюсера: запись
+// подтверждается всеми репликами
+func NewProducerConfig(cfg config.KafkaConfig) (*sarama.Config, error) {
+	sc, err := NewClientConfig(cfg.KafkaClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	sc.Producer.Return.Successes = true
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Retry.Max = 5
+	if cfg.InitialBackoff > 0 {
+		sc.Producer.Retry.Backoff = cfg.InitialBackoff
+	}
+	return validated(sc)
+}
+
+// NewLoadProducerConfig собирает настройки асинхронного продюсера подкоманды
+// producer: запись подтверждается всеми репликами, успехи и ошибки
+// возвращаются в каналы продюсера
+func NewLoadProducerConfig(cfg config.KafkaClientConfig) (*sarama.Config, error) {
+	sc, err := NewClientConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sc.Producer.Return.Successes = true
+	sc.Producer.Return.Errors = true
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Retry.Max = 5
+	return validated(sc)
+}
+
+// NewEventProducerConfig собирает настройки продюсера исходящих событий
+func NewEventProducerConfig(cfg config.KafkaConfig) (*sarama.Config, error) {
+	sc, err := NewClientConfig(cfg.KafkaClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	sc.Producer.Return.Successes = true
+	sc.Producer.Return.Errors = true
+	sc.Producer.Retry.Max = 5
+	// один ключ — одна партиция: события заказа читаются по порядку
+	sc.Producer.Partitioner = sarama.NewHashPartitioner
+
+	switch cfg.EventsAcks {
+	case "none":
+		sc.Producer.RequiredAcks = sarama.NoResponse
+	case "leader":
+		sc.Producer.RequiredAcks = sarama.WaitForLocal
+	default:
+		sc.Producer.RequiredAcks = sarama.WaitForAll
+	}
+	if cfg.EventsIdempotent {
+		// идемпотентность исключает дубли и перестановки при повторах
+		sc.Producer.Idempotent = true
+		sc.Net.MaxOpenRequests = 1
+	}
+	return validated(sc)
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"l0/internal/config"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSaramaConfig_ClientSettings(t *testing.T) {
+	sc, err := NewSaramaConfig(config.KafkaConfig{KafkaClientConfig: config.KafkaClientConfig{
+		ClientID:          "orders-service",
+		Version:           "3.6.0",
+		TLSEnabled:        true,
+		SASLMechanism:     sarama.SASLTypeSCRAMSHA512,
+		SASLUsername:      "app",
+		SASLPassword:      "secret",
+		FetchMaxBytes:     1 << 20,
+		SessionTimeout:    30 * time.Second,
+		HeartbeatInterval: 5 * time.Second,
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders-service", sc.ClientID)
+	assert.Equal(t, sarama.V3_6_0_0, sc.Version)
+	assert.True(t, sc.Net.TLS.Enable)
+	assert.True(t, sc.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), sc.Net.SASL.Mechanism)
+	require.NotNil(t, sc.Net.SASL.SCRAMClientGeneratorFunc)
+	require.NoError(t, sc.Net.SASL.SCRAMClientGeneratorFunc().Begin("app", "secret", ""))
+	assert.Equal(t, int32(1<<20), sc.Consumer.Fetch.Max)
+	assert.Equal(t, 30*time.Second, sc.Consumer.Group.Session.Timeout)
+	assert.Equal(t, 5*time.Second, sc.Consumer.Group.Heartbeat.Interval)
+}
+
+func TestNewLoadProducerConfig_Validates(t *testing.T) {
+	sc, err := NewLoadProducerConfig(config.KafkaClientConfig{ClientID: "orders-producer"})
+	require.NoError(t, err)
+	assert.True(t, sc.Producer.Return.Successes)
+	assert.Equal(t, sarama.WaitForAll, sc.Producer.RequiredAcks)
+
+	_, err = NewLoadProducerConfig(config.KafkaClientConfig{SessionTimeout: time.Second, HeartbeatInterval: 5 * time.Second})
+	assert.ErrorContains(t, err, "kafka config")
+}
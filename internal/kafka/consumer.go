@@ -2,181 +2,403 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"l0/internal/config"
 	"l0/internal/domain"
 	"log/slog"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
-	"github.com/go-playground/validator/v10"
 )
 
+// errConsumerPanic паника при обработке сообщений партиции
+var errConsumerPanic = errors.New("consumer panic")
+
 type DB interface {
 	CreateOrder(ctx context.Context, order domain.Order) (int, error)
+	// CreateOrders сохраняет пакет заказов одной транзакцией
+	CreateOrders(ctx context.Context, orders []domain.Order) ([]int, error)
 }
 
+// KafkaConsumer читает топики из handlers в составе группы потребителей.
+// Смещение сообщения отмечается только после его обработки или отправки в
+// DLQ, поэтому после перезапуска или ребалансировки чтение продолжается с
+// первого необработанного сообщения.
 type KafkaConsumer struct {
-	cfg          config.Config
-	logger       *slog.Logger
-	consumer     sarama.Consumer
-	orderService DB
-	validator    *validator.Validate
-	errChan      chan error
-	wg           sync.WaitGroup
-	topic        string
+	cfg         config.Config
+	logger      *slog.Logger
+	group       sarama.ConsumerGroup
+	handlers    *Handlers
+	deadLetters DeadLetterPublisher
+	// retries топики повторов; nil — повторы на месте с задержкой
+	retries *RetryScheduler
+	// offsets позиции партиций в БД; nil — только смещения Kafka
+	offsets OffsetStore
+	errors  *ErrorLog
+
+	// cancelSession завершает текущую сессию группы, чтобы перечитать
+	// сообщение, которое не удалось сохранить; sessionErr — ошибка сообщения,
+	// из-за которой сессия завершена, panicErr — паника обработки
+	mu            sync.Mutex
+	cancelSession context.CancelFunc
+	sessionErr    error
+	panicErr      error
+
+	// partitions назначенные партиции и ручное управление ими
+	partitions *partitions
+	metrics    *Metrics
 }
 
-func NewKafkaConsumer(cfg config.Config, logger *slog.Logger, consumer sarama.Consumer, orderService DB) *KafkaConsumer {
+func NewKafkaConsumer(cfg config.Config, logger *slog.Logger, group sarama.ConsumerGroup, handlers *Handlers, deadLetters DeadLetterPublisher, retries *RetryScheduler, offsets OffsetStore) *KafkaConsumer {
+	partitions := newPartitions()
 	return &KafkaConsumer{
-		cfg:          cfg,
-		logger:       logger,
-		consumer:     consumer,
-		orderService: orderService,
-		validator:    validator.New(),
-		errChan:      make(chan error, 10),
-		topic:        cfg.Kafka.Topic,
+		cfg:         cfg,
+		logger:      logger,
+		group:       group,
+		handlers:    handlers,
+		deadLetters: deadLetters,
+		retries:     retries,
+		offsets:     offsets,
+		errors:      newErrorLog(errorHistorySize),
+		partitions:  partitions,
+		metrics:     newMetrics(partitions),
 	}
 }
 
+// Consume участвует в группе до отмены ctx, заново входя в неё после каждой
+// ребалансировки. Возвращает ошибку, если группа не смогла провести сессию
+// или обработка сообщений запаниковала, а при KAFKA_MESSAGE_ERROR_POLICY=exit
+// — и *MessageError. Закрытие группы завершает Consume без ошибки.
 func (kc *KafkaConsumer) Consume(ctx context.Context) error {
-	partitions, err := kc.consumer.Partitions(kc.topic)
-	if err != nil {
-		return fmt.Errorf("get partitions: %w", err)
-	}
+	forwardCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go kc.forwardErrors(forwardCtx)
 
-	var mu sync.Mutex
-	var errs []error
+	topics := kc.handlers.Topics()
+	if kc.retries != nil {
+		topics = append(topics, kc.retries.Topics()...)
+	}
 
-	for _, partition := range partitions {
-		pc, err := kc.consumer.ConsumePartition(kc.topic, partition, sarama.OffsetNewest)
-		if err != nil {
-			mu.Lock()
-			errs = append(errs, fmt.Errorf("consume partition %d: %w", partition, err))
-			mu.Unlock()
-			kc.logger.Error("failed to consume partition", "partition", partition, "error", err)
-			continue
-		}
+	for {
+		sessCtx, cancel := context.WithCancel(ctx)
+		kc.mu.Lock()
+		kc.cancelSession = cancel
+		kc.mu.Unlock()
 
-		kc.wg.Add(1)
-		go kc.consumePartition(ctx, pc, partition, &mu, &errs)
-	}
+		err := kc.group.Consume(sessCtx, topics, kc)
+		restarted := sessCtx.Err() != nil
+		cancel()
 
-	kc.wg.Wait()
-	close(kc.errChan)
+		kc.mu.Lock()
+		sessionErr, panicErr := kc.sessionErr, kc.panicErr
+		kc.sessionErr, kc.panicErr = nil, nil
+		kc.mu.Unlock()
 
-	select {
-	case e := <-kc.errChan:
-		return e
-	default:
-		if len(errs) > 0 {
-			return errors.Join(errs...)
-		}
-		if ctx.Err() == context.Canceled {
+		if ctx.Err() != nil {
 			kc.logger.Info("context canceled, consumer finished")
 			return ctx.Err()
 		}
-		return nil
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return nil
+		}
+		if err != nil {
+			err = fmt.Errorf("consumer group: %w", err)
+			kc.logger.Error("consumer group session failed", "error", err)
+			kc.errors.record(ErrorFatal, err)
+			return err
+		}
+		// паника уже учтена в recoverPanic, политику применяет Supervisor
+		if panicErr != nil {
+			return panicErr
+		}
+		if sessionErr != nil && kc.cfg.Kafka.MessageErrorPolicy == "exit" {
+			return sessionErr
+		}
+		if restarted {
+			if !kc.sleep(ctx, kc.cfg.Kafka.InitialBackoff) {
+				return ctx.Err()
+			}
+		}
 	}
 }
 
-func (kc *KafkaConsumer) consumePartition(
-	ctx context.Context,
-	pc sarama.PartitionConsumer,
-	partition int32,
-	mu *sync.Mutex,
-	errs *[]error) {
+// Setup вызывается в начале сессии после распределения партиций
+func (kc *KafkaConsumer) Setup(sess sarama.ConsumerGroupSession) error {
+	kc.logger.Info("consumer group session started",
+		"member", sess.MemberID(),
+		"generation", sess.GenerationID(),
+		"claims", sess.Claims())
+	if err := kc.restoreOffsets(sess); err != nil {
+		return err
+	}
+	return kc.applySeeks(sess)
+}
+
+// Cleanup вызывается при завершении сессии, после него sarama фиксирует
+// отмеченные смещения
+func (kc *KafkaConsumer) Cleanup(sess sarama.ConsumerGroupSession) error {
+	kc.logger.Info("consumer group session finished", "generation", sess.GenerationID())
+	return nil
+}
+
+func (kc *KafkaConsumer) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) (err error) {
+	// sarama вызывает ConsumeClaim в своей горутине: без recover паника
+	// обработчика завершила бы процесс в обход KAFKA_FATAL_ERROR_POLICY
+	defer kc.recoverPanic(&err)
+	sess, release := kc.claim(sess, claim)
+	defer release()
 
-	defer kc.wg.Done()
-	defer func() {
-		if err := pc.Close(); err != nil {
-			kc.logger.Error("failed to close partition consumer", "partition", partition, "error", err)
-			mu.Lock()
-			*errs = append(*errs, err)
-			mu.Unlock()
+	if kc.retries != nil {
+		if tier, ok := kc.retries.Tier(claim.Topic()); ok {
+			handler, err := kc.handler(tier.Source)
+			if err != nil {
+				return err
+			}
+			return kc.consumeRetries(sess, claim, tier, handler)
 		}
-	}()
+	}
+	handler, err := kc.handler(claim.Topic())
+	if err != nil {
+		return err
+	}
+	if kc.cfg.Kafka.BatchSize > 1 {
+		return kc.consumeBatches(sess, claim, handler)
+	}
+	if kc.cfg.Kafka.Workers > 1 {
+		return kc.consumeParallel(sess, claim, handler)
+	}
 
+	ctx := sess.Context()
 	for {
 		select {
-		case msg, ok := <-pc.Messages():
+		case msg, ok := <-claim.Messages():
 			if !ok {
-				kc.logger.Info("message channel closed", "partition", partition)
-				return
+				kc.logger.Info("message channel closed", "partition", claim.Partition())
+				return nil
 			}
 
-			var order domain.Order
-			if err := json.Unmarshal(msg.Value, &order); err != nil {
-				kc.logger.Error("failed to unmarshal message", "error", err)
-				continue
+			if err := kc.processMessage(ctx, handler, msg); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				// смещение не отмечено: завершаем сессию, сообщение будет
+				// прочитано повторно с последнего зафиксированного смещения;
+				// ошибка попадёт в group.Errors()
+				kc.logger.Error("failed to process message, restarting session",
+					"partition", msg.Partition,
+					"offset", msg.Offset,
+					"error", err.Error())
+				return kc.failSession(msg, err)
 			}
+			sess.MarkMessage(msg, "")
 
-			if err := kc.validator.Struct(order); err != nil {
-				kc.logger.Error("validation failed", "error", err.Error())
-				continue
-			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
 
-			var procErr error
-			for attempt := 0; attempt <= kc.cfg.Kafka.MaxRetries; attempt++ {
-				_, procErr = kc.orderService.CreateOrder(ctx, order)
-				if procErr == nil {
-					break
-				}
-				if attempt < kc.cfg.Kafka.MaxRetries {
-					kc.logger.Warn("processing attempt failed",
-						"attempt", attempt,
-						"partition", partition,
-						"error", procErr.Error())
-					backoff := kc.cfg.Kafka.InitialBackoff * time.Duration(1<<attempt)
-					select {
-					case <-time.After(backoff):
-					case <-ctx.Done():
-						kc.logger.Info("context canceled during backoff", "partition", partition)
-						return
-					}
-				}
-			}
+// handler обработчик топика
+func (kc *KafkaConsumer) handler(topic string) (MessageHandler, error) {
+	handler, ok := kc.handlers.Handler(topic)
+	if !ok {
+		return nil, fmt.Errorf("no handler for topic %s", topic)
+	}
+	return handler, nil
+}
 
-			if procErr != nil {
-				mu.Lock()
-				*errs = append(*errs, procErr)
-				mu.Unlock()
+// processMessage обрабатывает сообщение, а при неудаче отправляет его в DLQ.
+// Ошибка возвращается, только если сообщение не удалось ни обработать, ни
+// отправить в DLQ — тогда смещение отмечать нельзя.
+func (kc *KafkaConsumer) processMessage(ctx context.Context, handler MessageHandler, msg *sarama.ConsumerMessage) error {
+	if kc.processed(msg) {
+		kc.logger.Debug("message already processed, skipping",
+			"topic", msg.Topic,
+			"partition", msg.Partition,
+			"offset", msg.Offset)
+		return nil
+	}
+	defer kc.metrics.observe(msg.Topic, time.Now())
+	failure := kc.handleMessage(ctx, handler, msg)
+	if failure == nil {
+		return nil
+	}
+	return kc.routeFailure(ctx, msg, failure)
+}
+
+// routeFailure отправляет сообщение, которое не удалось сохранить, на
+// следующий уровень повторов, а остальные необработанные сообщения — в DLQ
+func (kc *KafkaConsumer) routeFailure(ctx context.Context, msg *sarama.ConsumerMessage, failure *Failure) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	kc.metrics.failed.WithLabelValues(msg.Topic, failure.Stage).Inc()
+
+	if kc.retries != nil && failure.Stage == StagePersist {
+		scheduled, err := kc.retries.Schedule(ctx, msg, *failure)
+		if err != nil {
+			return fmt.Errorf("%s failed: %v; %w", failure.Stage, failure.Err, err)
+		}
+		if scheduled {
+			kc.metrics.retries.WithLabelValues(msg.Topic, retryTopic).Inc()
+			kc.logger.Warn("failed to handle message, scheduled for retry",
+				"topic", msg.Topic,
+				"partition", msg.Partition,
+				"offset", msg.Offset,
+				"attempt", RetryAttempt(msg)+1,
+				"error", failure.Err.Error())
+			return kc.saveOffset(ctx, msg)
+		}
+		failure.Attempts = RetryAttempt(msg) + 1
+	}
+
+	kc.logger.Error("message processing failed, sending to dlq",
+		"stage", failure.Stage,
+		"partition", msg.Partition,
+		"offset", msg.Offset,
+		"attempts", failure.Attempts,
+		"error", failure.Err.Error())
+	if err := kc.deadLetters.Publish(ctx, msg, *failure); err != nil {
+		return fmt.Errorf("%s failed: %v; %w", failure.Stage, failure.Err, err)
+	}
+	return kc.saveOffset(ctx, msg)
+}
+
+// handleMessage разбирает, проверяет и применяет сообщение
+func (kc *KafkaConsumer) handleMessage(ctx context.Context, handler MessageHandler, msg *sarama.ConsumerMessage) *Failure {
+	m, failure := handler.Decode(ctx, messageHeaders(msg), msg.Value)
+	if failure != nil {
+		return failure
+	}
+	return kc.apply(ctx, handler, msg, m)
+}
+
+// apply применяет разобранное сообщение с повторами
+func (kc *KafkaConsumer) apply(ctx context.Context, handler MessageHandler, msg *sarama.ConsumerMessage, m Message) *Failure {
+	attempts, err := kc.withRetries(ctx, msg, func() error {
+		return handler.Handle(kc.withOffset(ctx, msg), m)
+	})
+	if err != nil {
+		return &Failure{Stage: StagePersist, Err: err, Attempts: attempts}
+	}
+	return nil
+}
+
+// withRetries выполняет fn до MaxRetries+1 раз с экспоненциальной задержкой
+// и возвращает число попыток и последнюю ошибку. С топиками повторов
+// выполняется одна попытка: повторы не должны задерживать партицию.
+func (kc *KafkaConsumer) withRetries(ctx context.Context, msg *sarama.ConsumerMessage, fn func() error) (int, error) {
+	maxRetries := kc.cfg.Kafka.MaxRetries
+	if kc.retries != nil {
+		maxRetries = 0
+	}
+
+	var err error
+	attempts := 0
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		attempts++
+		if err = fn(); err == nil {
+			return attempts, nil
+		}
+		if attempt < maxRetries {
+			kc.metrics.retries.WithLabelValues(msg.Topic, retryInline).Inc()
+			kc.logger.Warn("processing attempt failed",
+				"attempt", attempt,
+				"topic", msg.Topic,
+				"partition", msg.Partition,
+				"error", err.Error())
+			if !kc.sleep(ctx, kc.cfg.Kafka.InitialBackoff*time.Duration(1<<attempt)) {
+				break
 			}
+		}
+	}
+	return attempts, err
+}
+
+// failSession учитывает ошибку сообщения и завершает сессию, чтобы сообщение
+// было прочитано повторно
+func (kc *KafkaConsumer) failSession(msg *sarama.ConsumerMessage, err error) error {
+	msgErr := &MessageError{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset, Err: err}
+	kc.errors.record(ErrorMessage, msgErr)
 
-		case err, ok := <-pc.Errors():
+	kc.mu.Lock()
+	kc.sessionErr = msgErr
+	kc.mu.Unlock()
+	kc.restartSession()
+	return msgErr
+}
+
+// recoverPanic вызывается отложенно в горутинах обработки: паника становится
+// фатальной ошибкой, сессия завершается, и Consume возвращает ошибку, чтобы
+// Supervisor перезапустил консьюмер или завершил процесс. err может быть nil.
+func (kc *KafkaConsumer) recoverPanic(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	panicErr := fmt.Errorf("%w: %v", errConsumerPanic, r)
+	kc.logger.Error("consumer panic, stopping session", "error", panicErr.Error(), "stack", string(debug.Stack()))
+	kc.errors.record(ErrorFatal, panicErr)
+
+	kc.mu.Lock()
+	if kc.panicErr == nil {
+		kc.panicErr = panicErr
+	}
+	kc.mu.Unlock()
+	kc.restartSession()
+	if err != nil {
+		*err = panicErr
+	}
+}
+
+func (kc *KafkaConsumer) restartSession() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.cancelSession != nil {
+		kc.cancelSession()
+	}
+}
+
+func (kc *KafkaConsumer) forwardErrors(ctx context.Context) {
+	for {
+		select {
+		case err, ok := <-kc.group.Errors():
 			if !ok {
-				kc.logger.Info("error channel closed", "partition", partition)
 				return
 			}
-			kc.logger.Error("partition consumer error", "error", err.Err)
-			mu.Lock()
-			*errs = append(*errs, err.Err)
-			mu.Unlock()
-			select {
-			case kc.errChan <- fmt.Errorf("partition consumer error: %w", err.Err):
-			case <-ctx.Done():
-				return
+			// ошибки сообщений и паники уже учтены в failSession и recoverPanic
+			var msgErr *MessageError
+			if errors.As(err, &msgErr) || errors.Is(err, errConsumerPanic) {
+				continue
 			}
-
+			kc.logger.Error("consumer group error", "error", err)
+			kc.errors.record(ErrorGroup, err)
 		case <-ctx.Done():
-			kc.logger.Info("context canceled, shutting down partition consumer", "partition", partition)
 			return
 		}
 	}
 }
 
+func (kc *KafkaConsumer) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (kc *KafkaConsumer) Close() error {
-	return kc.consumer.Close()
+	return kc.group.Close()
 }
 
-func (kc *KafkaConsumer) GetError() error {
-	select {
-	case err := <-kc.errChan:
-		return err
-	default:
-		return nil
-	}
+// Errors счётчики и история ошибок консьюмера
+func (kc *KafkaConsumer) Errors() *ErrorLog {
+	return kc.errors
 }
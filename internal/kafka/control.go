@@ -0,0 +1,383 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// ErrPartitionNotAssigned партиция не назначена этому экземпляру сервиса
+var ErrPartitionNotAssigned = errors.New("partition is not assigned to this instance")
+
+// Состояния партиции
+const (
+	PartitionConsuming = "consuming"
+	PartitionPaused    = "paused"
+)
+
+// PartitionState позиция и состояние назначенной партиции
+type PartitionState struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	State     string `json:"state"`
+	// Offset следующее сообщение к обработке: всё до него обработано
+	Offset        int64 `json:"offset"`
+	HighWaterMark int64 `json:"high_water_mark"`
+	Lag           int64 `json:"lag"`
+	// PendingSeek смещение, с которого партиция будет читаться после
+	// перезапуска сессии
+	PendingSeek *int64 `json:"pending_seek,omitempty"`
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitions назначенные партиции, их позиции и команды управления.
+// Пауза и перемотка переживают перезапуск сессии группы, если партиция
+// осталась за этим экземпляром.
+type partitions struct {
+	mu       sync.Mutex
+	assigned map[partitionKey]*claimState
+	paused   map[partitionKey]bool
+	seeks    map[partitionKey]int64
+	// restored позиции из БД на начало сессии
+	restored map[partitionKey]restoredPartition
+}
+
+// restoredPartition позиция партиции из БД: сообщения до next и из
+// processed уже обработаны
+type restoredPartition struct {
+	next      int64
+	processed map[int64]bool
+}
+
+type claimState struct {
+	claim  sarama.ConsumerGroupClaim
+	offset int64
+	// advanced позиция, до которой партиция сдвинута в БД
+	advanced int64
+}
+
+func newPartitions() *partitions {
+	return &partitions{
+		assigned: make(map[partitionKey]*claimState),
+		paused:   make(map[partitionKey]bool),
+		seeks:    make(map[partitionKey]int64),
+		restored: make(map[partitionKey]restoredPartition),
+	}
+}
+
+// states позиции, отставание и состояние назначенных партиций
+func (p *partitions) states() []PartitionState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]PartitionState, 0, len(p.assigned))
+	for key, st := range p.assigned {
+		ps := PartitionState{
+			Topic:         key.topic,
+			Partition:     key.partition,
+			State:         PartitionConsuming,
+			Offset:        st.offset,
+			HighWaterMark: st.claim.HighWaterMarkOffset(),
+		}
+		if p.paused[key] {
+			ps.State = PartitionPaused
+		}
+		if ps.Offset >= 0 && ps.HighWaterMark > ps.Offset {
+			ps.Lag = ps.HighWaterMark - ps.Offset
+		}
+		if seek, ok := p.seeks[key]; ok {
+			ps.PendingSeek = &seek
+		}
+		out = append(out, ps)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Topic != out[j].Topic {
+			return out[i].Topic < out[j].Topic
+		}
+		return out[i].Partition < out[j].Partition
+	})
+	return out
+}
+
+// claim регистрирует партицию на время ConsumeClaim и возвращает сессию,
+// отслеживающую отмеченные смещения
+func (kc *KafkaConsumer) claim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) (sarama.ConsumerGroupSession, func()) {
+	key := partitionKey{claim.Topic(), claim.Partition()}
+	state := &claimState{claim: claim, offset: claim.InitialOffset(), advanced: claim.InitialOffset()}
+
+	p := kc.partitions
+	p.mu.Lock()
+	p.assigned[key] = state
+	paused := p.paused[key]
+	p.mu.Unlock()
+
+	// пауза sarama действует только на текущих потребителей партиций
+	if paused && kc.group != nil {
+		kc.group.Pause(map[string][]int32{key.topic: {key.partition}})
+	}
+
+	tracking := &trackingSession{ConsumerGroupSession: sess, partitions: p, state: state, metrics: kc.metrics}
+	if kc.offsets != nil {
+		tracking.advance = func(next int64) { kc.advanceOffset(key, next) }
+	}
+
+	release := func() {
+		p.mu.Lock()
+		if p.assigned[key] == state {
+			delete(p.assigned, key)
+		}
+		next, advance := state.offset, state.offset > state.advanced
+		p.mu.Unlock()
+		// позиция в БД догоняет всё отмеченное за сессию
+		if advance && tracking.advance != nil {
+			tracking.advance(next)
+		}
+	}
+	return tracking, release
+}
+
+// applySeeks переносит отложенные перемотки в смещения новой сессии, а при
+// хранении позиций в БД — и в неё. Перемотка, которую не удалось записать в
+// БД, остаётся отложенной. Перемотки и паузы партиций, переданных другому
+// экземпляру, отбрасываются: иначе они сработали бы при случайном
+// назначении партиции намного позже команды.
+func (kc *KafkaConsumer) applySeeks(sess sarama.ConsumerGroupSession) error {
+	p := kc.partitions
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	claimed := make(map[partitionKey]bool)
+	for topic, parts := range sess.Claims() {
+		for _, partition := range parts {
+			claimed[partitionKey{topic, partition}] = true
+		}
+	}
+	for key := range p.seeks {
+		if !claimed[key] {
+			delete(p.seeks, key)
+			kc.logger.Warn("pending seek dropped, partition is no longer assigned", "topic", key.topic, "partition", key.partition)
+		}
+	}
+	for key := range p.paused {
+		if !claimed[key] {
+			delete(p.paused, key)
+			kc.logger.Warn("pause dropped, partition is no longer assigned", "topic", key.topic, "partition", key.partition)
+		}
+	}
+
+	for topic, parts := range sess.Claims() {
+		for _, partition := range parts {
+			key := partitionKey{topic, partition}
+			offset, ok := p.seeks[key]
+			if !ok {
+				continue
+			}
+			if kc.offsets != nil {
+				if err := kc.offsets.ResetOffset(sess.Context(), kc.cfg.Kafka.ConsumerGroup, topic, partition, offset); err != nil {
+					return fmt.Errorf("seek %s/%d: %w", topic, partition, err)
+				}
+				p.restored[key] = restoredPartition{next: offset}
+			}
+			// ResetOffset сдвигает смещение только назад, MarkOffset — только вперёд
+			sess.ResetOffset(topic, partition, offset, "")
+			sess.MarkOffset(topic, partition, offset, "")
+			delete(p.seeks, key)
+			kc.logger.Info("partition offset moved", "topic", topic, "partition", partition, "offset", offset)
+		}
+	}
+	return nil
+}
+
+// trackingSession запоминает отмеченные смещения партиции, считает
+// обработанные сообщения и периодически сдвигает позицию партиции в БД
+type trackingSession struct {
+	sarama.ConsumerGroupSession
+	partitions *partitions
+	state      *claimState
+	metrics    *Metrics
+	// advance сдвигает позицию партиции в БД; nil — позиции только в Kafka
+	advance func(next int64)
+}
+
+func (s *trackingSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.ConsumerGroupSession.MarkMessage(msg, metadata)
+	s.partitions.mu.Lock()
+	s.state.offset = max(s.state.offset, msg.Offset+1)
+	// смещения отмечаются непрерывным префиксом, поэтому всё до state.offset
+	// обработано
+	next := s.state.offset
+	advance := s.advance != nil && next-s.state.advanced >= offsetAdvanceStep
+	if advance {
+		s.state.advanced = next
+	}
+	s.partitions.mu.Unlock()
+	s.metrics.processed.WithLabelValues(msg.Topic).Inc()
+	if advance {
+		s.advance(next)
+	}
+}
+
+// OffsetResolver поиск смещений по времени, реализуется sarama.Client
+type OffsetResolver interface {
+	GetOffset(topic string, partitionID int32, time int64) (int64, error)
+}
+
+// ConsumerControl ручное управление чтением: пауза, перемотка и повторное
+// чтение с момента времени. Действует на партиции, назначенные этому
+// экземпляру сервиса.
+type ConsumerControl struct {
+	consumer *KafkaConsumer
+	offsets  OffsetResolver
+}
+
+func NewConsumerControl(consumer *KafkaConsumer, offsets OffsetResolver) *ConsumerControl {
+	return &ConsumerControl{consumer: consumer, offsets: offsets}
+}
+
+// Partitions состояние назначенных партиций
+func (c *ConsumerControl) Partitions() []PartitionState {
+	return c.consumer.partitions.states()
+}
+
+// Pause приостанавливает чтение партиций топика, без partitions — всех
+// назначенных партиций топика
+func (c *ConsumerControl) Pause(topic string, partitions []int32) error {
+	return c.setPaused(topic, partitions, true)
+}
+
+// Resume возобновляет чтение партиций топика
+func (c *ConsumerControl) Resume(topic string, partitions []int32) error {
+	return c.setPaused(topic, partitions, false)
+}
+
+func (c *ConsumerControl) setPaused(topic string, partitions []int32, paused bool) error {
+	p := c.consumer.partitions
+	p.mu.Lock()
+	parts, err := c.resolve(topic, partitions)
+	if err == nil {
+		for _, partition := range parts {
+			if paused {
+				p.paused[partitionKey{topic, partition}] = true
+			} else {
+				delete(p.paused, partitionKey{topic, partition})
+			}
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if group := c.consumer.group; group != nil {
+		if paused {
+			group.Pause(map[string][]int32{topic: parts})
+		} else {
+			group.Resume(map[string][]int32{topic: parts})
+		}
+	}
+	c.consumer.logger.Info("partitions state changed", "topic", topic, "partitions", parts, "paused", paused)
+	return nil
+}
+
+// Seek перематывает партицию на offset; sarama.OffsetOldest и
+// sarama.OffsetNewest означают начало и конец партиции
+func (c *ConsumerControl) Seek(topic string, partition int32, offset int64) (int64, error) {
+	if offset < 0 {
+		if offset != sarama.OffsetOldest && offset != sarama.OffsetNewest {
+			return 0, fmt.Errorf("invalid offset %d", offset)
+		}
+		resolved, err := c.offsets.GetOffset(topic, partition, offset)
+		if err != nil {
+			return 0, fmt.Errorf("resolve offset: %w", err)
+		}
+		offset = resolved
+	}
+
+	if err := c.seek(topic, map[int32]int64{partition: offset}); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// ReplayFrom перематывает партиции топика на первое сообщение не раньше at;
+// без partitions — все назначенные партиции топика. Партиции без таких
+// сообщений перематываются в конец.
+func (c *ConsumerControl) ReplayFrom(topic string, partitions []int32, at time.Time) (map[int32]int64, error) {
+	p := c.consumer.partitions
+	p.mu.Lock()
+	parts, err := c.resolve(topic, partitions)
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make(map[int32]int64, len(parts))
+	for _, partition := range parts {
+		offset, err := c.offsets.GetOffset(topic, partition, at.UnixMilli())
+		if err == nil && offset < 0 {
+			offset, err = c.offsets.GetOffset(topic, partition, sarama.OffsetNewest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolve offset of partition %d: %w", partition, err)
+		}
+		offsets[partition] = offset
+	}
+
+	if err := c.seek(topic, offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// seek запоминает смещения и перезапускает сессию: новая сессия начнёт
+// чтение с них
+func (c *ConsumerControl) seek(topic string, offsets map[int32]int64) error {
+	p := c.consumer.partitions
+	p.mu.Lock()
+	for partition := range offsets {
+		if _, ok := p.assigned[partitionKey{topic, partition}]; !ok {
+			p.mu.Unlock()
+			return fmt.Errorf("%s/%d: %w", topic, partition, ErrPartitionNotAssigned)
+		}
+	}
+	for partition, offset := range offsets {
+		p.seeks[partitionKey{topic, partition}] = offset
+	}
+	p.mu.Unlock()
+
+	c.consumer.logger.Info("partitions seek requested, restarting session", "topic", topic, "offsets", offsets)
+	c.consumer.restartSession()
+	return nil
+}
+
+// resolve проверяет, что партиции назначены; без partitions возвращает все
+// назначенные партиции топика. Вызывается под p.mu.
+func (c *ConsumerControl) resolve(topic string, partitions []int32) ([]int32, error) {
+	p := c.consumer.partitions
+	if len(partitions) == 0 {
+		for key := range p.assigned {
+			if key.topic == topic {
+				partitions = append(partitions, key.partition)
+			}
+		}
+		if len(partitions) == 0 {
+			return nil, fmt.Errorf("%s: %w", topic, ErrPartitionNotAssigned)
+		}
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+		return partitions, nil
+	}
+	for _, partition := range partitions {
+		if _, ok := p.assigned[partitionKey{topic, partition}]; !ok {
+			return nil, fmt.Errorf("%s/%d: %w", topic, partition, ErrPartitionNotAssigned)
+		}
+	}
+	return partitions, nil
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/config"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pausingGroup struct {
+	sarama.ConsumerGroup
+	paused, resumed []map[string][]int32
+}
+
+func (g *pausingGroup) Pause(p map[string][]int32)  { g.paused = append(g.paused, p) }
+func (g *pausingGroup) Resume(p map[string][]int32) { g.resumed = append(g.resumed, p) }
+
+type offsetsByTime map[int64]int64
+
+func (o offsetsByTime) GetOffset(_ string, _ int32, t int64) (int64, error) { return o[t], nil }
+
+// seekSession сессия новой генерации, в которую переносятся перемотки
+type seekSession struct {
+	fakeSession
+	reset, marked map[int32]int64
+}
+
+func (s *seekSession) Claims() map[string][]int32 { return map[string][]int32{"orders": {0}} }
+func (s *seekSession) MemberID() string           { return "member" }
+func (s *seekSession) GenerationID() int32        { return 2 }
+func (s *seekSession) ResetOffset(_ string, p int32, off int64, _ string) {
+	s.reset[p] = off
+}
+func (s *seekSession) MarkOffset(_ string, p int32, off int64, _ string) {
+	s.marked[p] = off
+}
+
+func TestConsumerControl(t *testing.T) {
+	group := &pausingGroup{}
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), group, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+	at := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	control := NewConsumerControl(kc, offsetsByTime{at.UnixMilli(): 40, sarama.OffsetNewest: 100})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 5)}
+	done := make(chan error)
+	go func() { done <- kc.ConsumeClaim(&fakeSession{ctx: ctx}, claim) }()
+
+	claim.messages <- orderMessage(t, "a", 0)
+	require.Eventually(t, func() bool {
+		p := control.Partitions()
+		return len(p) == 1 && p[0].Offset == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, control.Pause("orders", nil))
+	assert.Equal(t, []map[string][]int32{{"orders": {0}}}, group.paused)
+	assert.Equal(t, PartitionPaused, control.Partitions()[0].State)
+
+	_, err := control.ReplayFrom("orders", []int32{3}, at)
+	assert.ErrorIs(t, err, ErrPartitionNotAssigned)
+
+	offsets, err := control.ReplayFrom("orders", nil, at)
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]int64{0: 40}, offsets)
+	require.NotNil(t, control.Partitions()[0].PendingSeek)
+	assert.Equal(t, int64(40), *control.Partitions()[0].PendingSeek)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, control.Partitions(), "partition is released with its claim")
+
+	// новая сессия начинает с запрошенного смещения и остаётся на паузе
+	sess := &seekSession{fakeSession: fakeSession{ctx: context.Background()}, reset: map[int32]int64{}, marked: map[int32]int64{}}
+	require.NoError(t, kc.Setup(sess))
+	assert.Equal(t, int64(40), sess.reset[0])
+	assert.Equal(t, int64(40), sess.marked[0])
+
+	claim = &fakeClaim{messages: make(chan *sarama.ConsumerMessage)}
+	close(claim.messages)
+	require.NoError(t, kc.ConsumeClaim(sess, claim))
+	assert.Len(t, group.paused, 2, "pause is reapplied to the new claim")
+
+	require.ErrorIs(t, control.Resume("orders", nil), ErrPartitionNotAssigned)
+}
+
+func TestConsumer_SetupDropsUnassignedSeeks(t *testing.T) {
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), &pausingGroup{}, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+	kc.partitions.seeks[partitionKey{"orders", 0}] = 7
+	kc.partitions.seeks[partitionKey{"orders", 5}] = 40
+	kc.partitions.paused[partitionKey{"orders", 5}] = true
+
+	sess := &seekSession{fakeSession: fakeSession{ctx: context.Background()}, reset: map[int32]int64{}, marked: map[int32]int64{}}
+	require.NoError(t, kc.Setup(sess))
+	assert.Equal(t, map[int32]int64{0: 7}, sess.reset)
+	assert.Empty(t, kc.partitions.seeks)
+	assert.Empty(t, kc.partitions.paused, "partition 5 is assigned to another instance")
+}
@@ -0,0 +1,242 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"l0/internal/domain"
+	"l0/internal/schema"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HeaderContentType заголовок с форматом тела сообщения; без заголовка
+// тело считается JSON
+const HeaderContentType = "content-type"
+
+// HeaderSchemaVersion заголовок с версией схемы тела. Без заголовка версия
+// берётся из поля schema_version тела JSON; JSON без версии считается
+// моделью поставщика (версия 1), Avro и Protobuf — текущей версией.
+const HeaderSchemaVersion = "schema-version"
+
+// Форматы тела сообщения
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+)
+
+// Decoder разбирает заказ из тела сообщения одного формата. version — версия
+// схемы из заголовка, 0 если не указана.
+type Decoder interface {
+	Decode(ctx context.Context, version int, value []byte) (domain.Order, error)
+}
+
+// Decoders выбирает Decoder по заголовку content-type
+type Decoders struct {
+	byType map[string]Decoder
+}
+
+// jsonDecoders только JSON, когда другие форматы не настроены
+func jsonDecoders() *Decoders {
+	d := &Decoders{byType: make(map[string]Decoder)}
+	d.Register(NewJSONDecoder(), ContentTypeJSON, "json", "text/json")
+	return d
+}
+
+// NewDecoders JSON, Protobuf и Avro. registry может быть nil: тогда
+// сообщения в формате реестра не поддерживаются, а Avro читается по
+// встроенной схеме заказа.
+func NewDecoders(registry schema.Registry) (*Decoders, error) {
+	avro, err := NewAvroDecoder(registry)
+	if err != nil {
+		return nil, err
+	}
+	d := jsonDecoders()
+	d.Register(&ProtobufDecoder{registry: registry}, ContentTypeProtobuf, "protobuf",
+		"application/protobuf", "application/vnd.google.protobuf")
+	d.Register(avro, ContentTypeAvro, "avro", "avro/binary", "application/vnd.apache.avro+binary")
+	return d, nil
+}
+
+// Register назначает decoder форматам
+func (d *Decoders) Register(decoder Decoder, contentTypes ...string) {
+	for _, ct := range contentTypes {
+		d.byType[strings.ToLower(ct)] = decoder
+	}
+}
+
+// Decode разбирает заказ декодером формата contentType
+func (d *Decoders) Decode(ctx context.Context, ct string, version int, value []byte) (domain.Order, error) {
+	dec, ok := d.byType[contentType(ct)]
+	if !ok {
+		return domain.Order{}, fmt.Errorf("unsupported content-type %q", ct)
+	}
+	return dec.Decode(ctx, version, value)
+}
+
+// contentType тип из заголовка content-type без параметров; без заголовка — JSON
+func contentType(header string) string {
+	if header == "" {
+		return ContentTypeJSON
+	}
+	if mt, _, err := mime.ParseMediaType(header); err == nil {
+		return mt
+	}
+	return strings.ToLower(header)
+}
+
+// JSONDecoder заказ в JSON любой известной версии
+type JSONDecoder struct {
+	upcasters *schema.Upcasters
+}
+
+func NewJSONDecoder() *JSONDecoder {
+	return &JSONDecoder{upcasters: schema.OrderUpcasters()}
+}
+
+func (d *JSONDecoder) Decode(_ context.Context, version int, value []byte) (domain.Order, error) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return domain.Order{}, err
+	}
+
+	if version == 0 {
+		version = schema.OrderVersionLegacy
+		if v, ok := doc[schema.VersionField]; ok {
+			n, err := strconv.Atoi(fmt.Sprint(v))
+			if err != nil {
+				return domain.Order{}, fmt.Errorf("invalid %s %v", schema.VersionField, v)
+			}
+			version = n
+		}
+	}
+	return upcastOrder(d.upcasters, version, doc)
+}
+
+// upcastOrder приводит документ к текущей версии и разбирает заказ
+func upcastOrder(upcasters *schema.Upcasters, version int, doc map[string]any) (domain.Order, error) {
+	doc, err := upcasters.Upcast(version, doc)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	var order domain.Order
+	err = json.Unmarshal(raw, &order)
+	return order, err
+}
+
+// ProtobufDecoder заказ по order.proto, с заголовком реестра или без него
+type ProtobufDecoder struct {
+	registry schema.Registry
+}
+
+// Decode номера полей Protobuf обеспечивают совместимость со старыми
+// версиями, поэтому отклоняются только версии новее текущей
+func (d *ProtobufDecoder) Decode(ctx context.Context, version int, value []byte) (domain.Order, error) {
+	if version > schema.OrderVersion {
+		return domain.Order{}, fmt.Errorf("%w %d, latest is %d", schema.ErrFutureVersion, version, schema.OrderVersion)
+	}
+	if id, payload, ok := schema.SplitWire(value); ok {
+		if d.registry == nil {
+			return domain.Order{}, fmt.Errorf("message references schema %d, but no schema registry is configured", id)
+		}
+		s, err := d.registry.SchemaByID(ctx, id)
+		if err != nil {
+			return domain.Order{}, err
+		}
+		if s.SchemaType() != schema.TypeProtobuf {
+			return domain.Order{}, fmt.Errorf("schema %d is %s, not %s", id, s.SchemaType(), schema.TypeProtobuf)
+		}
+		if value, err = schema.SkipMessageIndexes(payload); err != nil {
+			return domain.Order{}, err
+		}
+	}
+	return schema.UnmarshalOrderProto(value)
+}
+
+// AvroDecoder заказ в Avro. Сообщение с заголовком реестра читается по
+// схеме писателя из реестра, без заголовка — по встроенной схеме заказа.
+// Поля сопоставляются с domain.Order по именам, поэтому добавленные и
+// удалённые в новых версиях схемы поля не мешают чтению.
+type AvroDecoder struct {
+	registry  schema.Registry
+	builtin   *schema.Avro
+	upcasters *schema.Upcasters
+
+	mu   sync.RWMutex
+	byID map[int]*schema.Avro
+}
+
+func NewAvroDecoder(registry schema.Registry) (*AvroDecoder, error) {
+	builtin, err := schema.ParseAvro(schema.OrderAvro)
+	if err != nil {
+		return nil, err
+	}
+	return &AvroDecoder{
+		registry:  registry,
+		builtin:   builtin,
+		upcasters: schema.OrderUpcasters(),
+		byID:      make(map[int]*schema.Avro),
+	}, nil
+}
+
+func (d *AvroDecoder) Decode(ctx context.Context, version int, value []byte) (domain.Order, error) {
+	writer := d.builtin
+	if d.registry != nil {
+		if id, payload, ok := schema.SplitWire(value); ok {
+			var err error
+			if writer, err = d.schema(ctx, id); err != nil {
+				return domain.Order{}, err
+			}
+			value = payload
+		}
+	}
+
+	v, err := writer.Decode(value)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return domain.Order{}, fmt.Errorf("avro value is %T, not a record", v)
+	}
+	if version == 0 {
+		version = schema.OrderVersion
+	}
+	// значения Avro совпадают с JSON-представлением заказа
+	return upcastOrder(d.upcasters, version, doc)
+}
+
+func (d *AvroDecoder) schema(ctx context.Context, id int) (*schema.Avro, error) {
+	d.mu.RLock()
+	a, ok := d.byID[id]
+	d.mu.RUnlock()
+	if ok {
+		return a, nil
+	}
+
+	s, err := d.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.SchemaType() != schema.TypeAvro {
+		return nil, fmt.Errorf("schema %d is %s, not %s", id, s.SchemaType(), schema.TypeAvro)
+	}
+	if a, err = schema.ParseAvro(s.Schema); err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+
+	d.mu.Lock()
+	d.byID[id] = a
+	d.mu.Unlock()
+	return a, nil
+}
@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"l0/internal/schema"
+	"log/slog"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoders_Formats(t *testing.T) {
+	ctx := context.Background()
+	msg := orderMessage(t, "b563feb7b2b84b6test", 0)
+	var want domain.Order
+	require.NoError(t, json.Unmarshal(msg.Value, &want))
+
+	registry, err := schema.NewFileRegistry(t.TempDir())
+	require.NoError(t, err)
+	avroID, err := registry.Register(ctx, schema.ValueSubject("orders"), schema.TypeAvro, schema.OrderAvro)
+	require.NoError(t, err)
+	protoID, err := registry.Register(ctx, schema.ValueSubject("orders-proto"), schema.TypeProtobuf, schema.OrderProto)
+	require.NoError(t, err)
+
+	decoders, err := NewDecoders(registry)
+	require.NoError(t, err)
+
+	avro, err := schema.ParseAvro(schema.OrderAvro)
+	require.NoError(t, err)
+	avroBody, err := avro.EncodeJSON(msg.Value)
+	require.NoError(t, err)
+	protoBody := schema.MarshalOrderProto(want)
+
+	cases := []struct {
+		name        string
+		contentType string
+		value       []byte
+	}{
+		{"json without header", "", msg.Value},
+		{"json with charset", "application/json; charset=utf-8", msg.Value},
+		{"protobuf", ContentTypeProtobuf, protoBody},
+		{"protobuf framed", "application/protobuf", append(schema.AppendWire(nil, protoID), append([]byte{0}, protoBody...)...)},
+		{"avro", ContentTypeAvro, avroBody},
+		{"avro framed", "avro", append(schema.AppendWire(nil, avroID), avroBody...)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decoders.Decode(ctx, tc.contentType, 0, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	_, err = decoders.Decode(ctx, "application/xml", 0, msg.Value)
+	assert.ErrorContains(t, err, "unsupported content-type")
+
+	// схема из реестра другого типа
+	_, err = decoders.Decode(ctx, ContentTypeProtobuf, 0, append(schema.AppendWire(nil, avroID), 0))
+	assert.ErrorContains(t, err, "not PROTOBUF")
+}
+
+func TestKafkaConsumer_ProcessMessage_FutureVersionToDLQ(t *testing.T) {
+	dlq := &recordingDLQ{}
+	db := &fakeDB{}
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, orderHandlers(db), dlq, nil, nil)
+
+	msg := orderMessage(t, "future", 0)
+	msg.Headers = []*sarama.RecordHeader{{Key: []byte(HeaderSchemaVersion), Value: []byte("3")}}
+	require.NoError(t, kc.processMessage(context.Background(), ordersHandler(kc), msg))
+
+	require.Len(t, dlq.failures, 1)
+	assert.Equal(t, StageDecode, dlq.failures[0].Stage)
+	assert.ErrorIs(t, dlq.failures[0].Err, schema.ErrFutureVersion)
+	assert.Empty(t, db.stored)
+
+	// версия в теле тоже учитывается
+	msg = orderMessage(t, "future-body", 1)
+	msg.Value = bytes.Replace(msg.Value, []byte("{"), []byte(`{"schema_version":3,`), 1)
+	require.NoError(t, kc.processMessage(context.Background(), ordersHandler(kc), msg))
+	require.Len(t, dlq.failures, 2)
+	assert.ErrorIs(t, dlq.failures[1].Err, schema.ErrFutureVersion)
+}
@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"l0/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Этапы обработки, на которых сообщение может попасть в DLQ
+const (
+	StageDecode   = "decode"
+	StageValidate = "validate"
+	StagePersist  = "persist"
+)
+
+// Заголовки сообщения в DLQ
+const (
+	HeaderDLQStage           = "x-dlq-stage"
+	HeaderDLQError           = "x-dlq-error"
+	HeaderDLQAttempts        = "x-dlq-attempts"
+	HeaderDLQSourceTopic     = "x-dlq-source-topic"
+	HeaderDLQSourcePartition = "x-dlq-source-partition"
+	HeaderDLQSourceOffset    = "x-dlq-source-offset"
+	HeaderDLQSourceTimestamp = "x-dlq-source-timestamp"
+	HeaderDLQFailedAt        = "x-dlq-failed-at"
+)
+
+// Failure описывает, почему сообщение не удалось обработать
+type Failure struct {
+	Stage      string
+	Err        error
+	Attempts   int
+	Violations []domain.Violation
+}
+
+func (f *Failure) Error() string {
+	return f.Stage + ": " + f.Err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// DeadLetterPublisher отправляет необработанные сообщения в DLQ
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, msg *sarama.ConsumerMessage, failure Failure) error
+}
+
+// DeadLetterQueue публикует сообщения в топик DLQ с исходными ключом,
+// значением и заголовками и добавляет заголовки x-dlq-* с причиной ошибки
+type DeadLetterQueue struct {
+	producer sarama.SyncProducer
+	topic    string
+	now      func() time.Time
+}
+
+func NewDeadLetterQueue(producer sarama.SyncProducer, topic string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		producer: producer,
+		topic:    topic,
+		now:      time.Now,
+	}
+}
+
+func (q *DeadLetterQueue) Publish(_ context.Context, msg *sarama.ConsumerMessage, failure Failure) error {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+8)
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers = append(headers, *h)
+		}
+	}
+	errText := ""
+	if failure.Err != nil {
+		errText = failure.Err.Error()
+	}
+	headers = append(headers,
+		header(HeaderDLQStage, failure.Stage),
+		header(HeaderDLQError, errText),
+		header(HeaderDLQAttempts, strconv.Itoa(failure.Attempts)),
+		header(HeaderDLQSourceTopic, msg.Topic),
+		header(HeaderDLQSourcePartition, strconv.FormatInt(int64(msg.Partition), 10)),
+		header(HeaderDLQSourceOffset, strconv.FormatInt(msg.Offset, 10)),
+		header(HeaderDLQSourceTimestamp, msg.Timestamp.UTC().Format(time.RFC3339Nano)),
+		header(HeaderDLQFailedAt, q.now().UTC().Format(time.RFC3339Nano)),
+	)
+
+	out := &sarama.ProducerMessage{
+		Topic:   q.topic,
+		Headers: headers,
+	}
+	if msg.Key != nil {
+		out.Key = sarama.ByteEncoder(msg.Key)
+	}
+	if msg.Value != nil {
+		out.Value = sarama.ByteEncoder(msg.Value)
+	}
+
+	if _, _, err := q.producer.SendMessage(out); err != nil {
+		return fmt.Errorf("publish to dlq %s: %w", q.topic, err)
+	}
+	return nil
+}
+
+func (q *DeadLetterQueue) Close() error {
+	return q.producer.Close()
+}
+
+// DeadLetterPublishers отправляет сообщение во все приёмники по порядку,
+// например в карантин и в топик DLQ. Отправка останавливается на первой
+// ошибке, а сообщение не отмечается и доставляется повторно, поэтому все
+// приёмники, кроме последнего, должны быть идемпотентны.
+type DeadLetterPublishers []DeadLetterPublisher
+
+func (ps DeadLetterPublishers) Publish(ctx context.Context, msg *sarama.ConsumerMessage, failure Failure) error {
+	for _, p := range ps {
+		if err := p.Publish(ctx, msg, failure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func header(key, value string) sarama.RecordHeader {
+	return sarama.RecordHeader{Key: []byte(key), Value: []byte(value)}
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"l0/internal/config"
+	"l0/internal/rules"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingDLQ struct {
+	failures []Failure
+	err      error
+}
+
+func (r *recordingDLQ) Publish(_ context.Context, _ *sarama.ConsumerMessage, failure Failure) error {
+	r.failures = append(r.failures, failure)
+	return r.err
+}
+
+func TestDeadLetterQueue_Publish(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, "orders.dlq", msg.Topic)
+
+		headers := make(map[string]string, len(msg.Headers))
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		assert.Equal(t, "json", headers["content-type"], "original headers are kept")
+		assert.Equal(t, StagePersist, headers[HeaderDLQStage])
+		assert.Equal(t, "db is down", headers[HeaderDLQError])
+		assert.Equal(t, "4", headers[HeaderDLQAttempts])
+		assert.Equal(t, "orders", headers[HeaderDLQSourceTopic])
+		assert.Equal(t, "2", headers[HeaderDLQSourcePartition])
+		assert.Equal(t, "17", headers[HeaderDLQSourceOffset])
+		assert.Equal(t, "2024-01-02T03:04:05Z", headers[HeaderDLQSourceTimestamp])
+
+		key, _ := msg.Key.Encode()
+		value, _ := msg.Value.Encode()
+		assert.Equal(t, "key", string(key))
+		assert.Equal(t, `{"order_uid":"x"}`, string(value))
+		return nil
+	})
+
+	q := NewDeadLetterQueue(producer, "orders.dlq")
+	err := q.Publish(context.Background(), &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    17,
+		Key:       []byte("key"),
+		Value:     []byte(`{"order_uid":"x"}`),
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("content-type"), Value: []byte("json")}},
+	}, Failure{Stage: StagePersist, Err: errors.New("db is down"), Attempts: 4})
+	require.NoError(t, err)
+	require.NoError(t, producer.Close())
+}
+
+func TestKafkaConsumer_ProcessMessage_InvalidToDLQ(t *testing.T) {
+	dlq := &recordingDLQ{}
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, orderHandlers(nil), dlq, nil, nil)
+
+	require.NoError(t, kc.processMessage(context.Background(), ordersHandler(kc), &sarama.ConsumerMessage{Value: []byte("not json")}))
+	require.NoError(t, kc.processMessage(context.Background(), ordersHandler(kc), &sarama.ConsumerMessage{Value: []byte(`{}`)}))
+
+	require.Len(t, dlq.failures, 2)
+	assert.Equal(t, StageDecode, dlq.failures[0].Stage)
+	assert.Equal(t, StageValidate, dlq.failures[1].Stage)
+
+	// сообщение, которое не удалось отправить в DLQ, не должно быть отмечено
+	dlq.err = errors.New("broker unavailable")
+	assert.Error(t, kc.processMessage(context.Background(), ordersHandler(kc), &sarama.ConsumerMessage{Value: []byte("not json")}))
+}
+
+func TestPipeline_RulesRejectToDLQ(t *testing.T) {
+	set, err := rules.Parse([]byte("rules:\n  - {id: wbil-currency, entries: [WBIL], field: Payment.Currency, assert: 'Payment.Currency == \"EUR\"'}\n"))
+	require.NoError(t, err)
+
+	dlq := &recordingDLQ{}
+	db := &fakeDB{}
+	h := NewHandlers()
+	h.Register("orders", NewOrderHandler(NewPipeline(db, nil, set)))
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, h, dlq, nil, nil)
+
+	require.NoError(t, kc.processMessage(context.Background(), ordersHandler(kc), orderMessage(t, "usd", 0)))
+
+	require.Len(t, dlq.failures, 1)
+	assert.Equal(t, StageValidate, dlq.failures[0].Stage)
+	assert.ErrorContains(t, dlq.failures[0].Err, "[wbil-currency]")
+	require.Len(t, dlq.failures[0].Violations, 1)
+	assert.Equal(t, "USD", dlq.failures[0].Violations[0].Actual)
+	assert.Empty(t, db.stored)
+}
+
+func TestDeadLetterPublishers_StopsOnError(t *testing.T) {
+	quarantine := &recordingDLQ{err: errors.New("db is down")}
+	topic := &recordingDLQ{}
+	ps := DeadLetterPublishers{quarantine, topic}
+
+	msg := &sarama.ConsumerMessage{Topic: "orders", Offset: 3}
+	assert.Error(t, ps.Publish(context.Background(), msg, Failure{Stage: StageDecode}))
+	assert.Empty(t, topic.failures, "message is not published to the dlq topic until quarantine succeeds")
+
+	quarantine.err = nil
+	require.NoError(t, ps.Publish(context.Background(), msg, Failure{Stage: StageDecode}))
+	assert.Len(t, quarantine.failures, 2)
+	assert.Len(t, topic.failures, 1)
+}
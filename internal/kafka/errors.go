@@ -0,0 +1,114 @@
+package kafka
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrorKind вид ошибки консьюмера
+type ErrorKind string
+
+const (
+	// ErrorFatal группа не смогла провести сессию или консьюмер упал
+	ErrorFatal ErrorKind = "fatal"
+	// ErrorMessage сообщение не удалось ни сохранить, ни отправить в DLQ
+	ErrorMessage ErrorKind = "message"
+	// ErrorGroup прочие ошибки клиента из group.Errors()
+	ErrorGroup ErrorKind = "group"
+)
+
+// errorHistorySize сколько последних ошибок хранить
+const errorHistorySize = 20
+
+// MessageError сообщение, из-за которого сессия перезапущена
+type MessageError struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("%s/%d at offset %d: %v", e.Topic, e.Partition, e.Offset, e.Err)
+}
+
+func (e *MessageError) Unwrap() error { return e.Err }
+
+// ErrorRecord ошибка из истории
+type ErrorRecord struct {
+	Kind  ErrorKind `json:"kind"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// ErrorStats число ошибок по видам и последние ошибки, от новых к старым
+type ErrorStats struct {
+	Counts map[ErrorKind]int64 `json:"counts"`
+	Recent []ErrorRecord       `json:"recent,omitempty"`
+}
+
+// ErrorLog счётчики ошибок и кольцевой буфер последних из них: память не
+// растёт, сколько бы сообщений ни падало
+type ErrorLog struct {
+	mu     sync.Mutex
+	counts map[ErrorKind]int64
+	recent []ErrorRecord
+	next   int
+}
+
+func newErrorLog(size int) *ErrorLog {
+	return &ErrorLog{
+		counts: make(map[ErrorKind]int64),
+		recent: make([]ErrorRecord, 0, size),
+	}
+}
+
+func (l *ErrorLog) record(kind ErrorKind, err error) {
+	rec := ErrorRecord{Kind: kind, Error: err.Error(), At: time.Now()}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.counts[kind]++
+	if len(l.recent) < cap(l.recent) {
+		l.recent = append(l.recent, rec)
+	} else {
+		l.recent[l.next] = rec
+	}
+	l.next = (l.next + 1) % cap(l.recent)
+}
+
+// Stats копия счётчиков и истории
+func (l *ErrorLog) Stats() ErrorStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := ErrorStats{
+		Counts: make(map[ErrorKind]int64, len(l.counts)),
+		Recent: make([]ErrorRecord, 0, len(l.recent)),
+	}
+	for kind, n := range l.counts {
+		stats.Counts[kind] = n
+	}
+	for i := range len(l.recent) {
+		idx := (l.next - 1 - i + 2*len(l.recent)) % len(l.recent)
+		stats.Recent = append(stats.Recent, l.recent[idx])
+	}
+	return stats
+}
+
+// lastAt время последней ошибки одного из видов kinds
+func (l *ErrorLog) lastAt(kinds ...ErrorKind) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var last time.Time
+	for _, rec := range l.recent {
+		for _, kind := range kinds {
+			if rec.Kind == kind && rec.At.After(last) {
+				last = rec.At
+			}
+		}
+	}
+	return last
+}
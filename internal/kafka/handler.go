@@ -0,0 +1,212 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"l0/internal/domain"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Имена обработчиков для KAFKA_TOPIC_HANDLERS
+const (
+	HandlerPayment        = "payment"
+	HandlerDeliveryStatus = "delivery_status"
+)
+
+// Message разобранное и проверенное сообщение топика
+type Message interface {
+	// OrderingKey ключ, порядок сообщений с которым сохраняется при
+	// параллельной обработке. by — KAFKA_ORDERING_KEY; пустой by — ключ
+	// для сообщений без ключа Kafka.
+	OrderingKey(by string) string
+}
+
+// MessageHandler разбор, проверка и обработка сообщений одного топика.
+// Повторы, DLQ, метрики и смещения общие для всех обработчиков.
+type MessageHandler interface {
+	// Decode разбирает и проверяет тело сообщения
+	Decode(ctx context.Context, headers map[string]string, value []byte) (Message, *Failure)
+	// Handle применяет разобранное сообщение
+	Handle(ctx context.Context, msg Message) error
+}
+
+// BatchHandler обработчик, применяющий пакет сообщений одной транзакцией
+type BatchHandler interface {
+	MessageHandler
+	HandleBatch(ctx context.Context, msgs []Message) error
+}
+
+// Handlers обработчики сообщений по топикам
+type Handlers struct {
+	byTopic map[string]MessageHandler
+}
+
+func NewHandlers() *Handlers {
+	return &Handlers{byTopic: make(map[string]MessageHandler)}
+}
+
+// Register назначает обработчик топику
+func (h *Handlers) Register(topic string, handler MessageHandler) {
+	h.byTopic[topic] = handler
+}
+
+// Handler обработчик топика
+func (h *Handlers) Handler(topic string) (MessageHandler, bool) {
+	handler, ok := h.byTopic[topic]
+	return handler, ok
+}
+
+// Ingest обрабатывает тело сообщения карантина за одну попытку
+// обработчиком исходного топика: для сообщений из топиков повторов — топика
+// из заголовка x-retry-origin. Для заказов возвращает ID заказа, для
+// остальных топиков 0. Ошибка обработки возвращается как *Failure.
+func (h *Handlers) Ingest(ctx context.Context, topic string, headers map[string]string, value []byte) (int, error) {
+	topic = originTopic(topic, headers)
+	handler, ok := h.Handler(topic)
+	if !ok {
+		return 0, &Failure{Stage: StageDecode, Err: fmt.Errorf("no handler for topic %s", topic), Attempts: 1}
+	}
+	if ing, ok := handler.(ingester); ok {
+		return ing.Ingest(ctx, headers, value)
+	}
+	msg, failure := handler.Decode(ctx, headers, value)
+	if failure != nil {
+		return 0, failure
+	}
+	if err := handler.Handle(ctx, msg); err != nil {
+		return 0, &Failure{Stage: StagePersist, Err: err, Attempts: 1}
+	}
+	return 0, nil
+}
+
+// ingester обработчик, возвращающий ID сохранённой записи
+type ingester interface {
+	Ingest(ctx context.Context, headers map[string]string, value []byte) (int, error)
+}
+
+// Topics топики с обработчиками по алфавиту
+func (h *Handlers) Topics() []string {
+	topics := make([]string, 0, len(h.byTopic))
+	for topic := range h.byTopic {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// OrderMessage заказ из топика заказов
+type OrderMessage struct {
+	domain.Order
+}
+
+func (m OrderMessage) OrderingKey(by string) string {
+	switch by {
+	case "customer_id":
+		return m.CustomerID
+	case "", "order_uid":
+		return m.OrderUID
+	}
+	return ""
+}
+
+// OrderHandler сохраняет заказы, разобранные Pipeline
+type OrderHandler struct {
+	pipeline *Pipeline
+}
+
+func NewOrderHandler(pipeline *Pipeline) *OrderHandler {
+	return &OrderHandler{pipeline: pipeline}
+}
+
+func (h *OrderHandler) Decode(ctx context.Context, headers map[string]string, value []byte) (Message, *Failure) {
+	order, failure := h.pipeline.Decode(ctx, headers, value)
+	if failure != nil {
+		return nil, failure
+	}
+	return OrderMessage{order}, nil
+}
+
+func (h *OrderHandler) Handle(ctx context.Context, msg Message) error {
+	order := msg.(OrderMessage).Order
+	if _, err := h.pipeline.db.CreateOrder(ctx, order); err != nil {
+		return fmt.Errorf("store order %s: %w", order.OrderUID, err)
+	}
+	return nil
+}
+
+// Ingest разбирает и сохраняет заказ, возвращает его ID
+func (h *OrderHandler) Ingest(ctx context.Context, headers map[string]string, value []byte) (int, error) {
+	return h.pipeline.Ingest(ctx, headers, value)
+}
+
+func (h *OrderHandler) HandleBatch(ctx context.Context, msgs []Message) error {
+	orders := make([]domain.Order, len(msgs))
+	for i, msg := range msgs {
+		orders[i] = msg.(OrderMessage).Order
+	}
+	_, err := h.pipeline.db.CreateOrders(ctx, orders)
+	return err
+}
+
+// jsonMessage сообщение JSONHandler
+type jsonMessage[T any] struct {
+	value T
+	key   string
+}
+
+func (m jsonMessage[T]) OrderingKey(string) string { return m.key }
+
+// JSONHandler сообщения в JSON, проверяемые тегами validate и применяемые
+// функцией handle. key — ключ порядка сообщения, обычно OrderUID.
+type JSONHandler[T any] struct {
+	validator *validator.Validate
+	handle    func(ctx context.Context, v T) error
+	key       func(v T) string
+}
+
+func NewJSONHandler[T any](handle func(ctx context.Context, v T) error, key func(v T) string) *JSONHandler[T] {
+	return &JSONHandler[T]{
+		validator: validator.New(),
+		handle:    handle,
+		key:       key,
+	}
+}
+
+func (h *JSONHandler[T]) Decode(_ context.Context, headers map[string]string, value []byte) (Message, *Failure) {
+	if ct := headers[HeaderContentType]; ct != "" && contentType(ct) != ContentTypeJSON {
+		return nil, &Failure{Stage: StageDecode, Err: fmt.Errorf("unsupported content-type %q", ct), Attempts: 1}
+	}
+	var v T
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, &Failure{Stage: StageDecode, Err: err, Attempts: 1}
+	}
+	if err := h.validator.Struct(v); err != nil {
+		return nil, &Failure{Stage: StageValidate, Err: err, Attempts: 1, Violations: violations(err)}
+	}
+	return jsonMessage[T]{value: v, key: h.key(v)}, nil
+}
+
+func (h *JSONHandler[T]) Handle(ctx context.Context, msg Message) error {
+	return h.handle(ctx, msg.(jsonMessage[T]).value)
+}
+
+// UpdatesStore хранилище подтверждений оплаты и статусов доставки
+type UpdatesStore interface {
+	SavePaymentConfirmation(ctx context.Context, c domain.PaymentConfirmation) error
+	SaveDeliveryStatus(ctx context.Context, u domain.DeliveryStatusUpdate) error
+}
+
+// NewPaymentHandler подтверждения оплаты
+func NewPaymentHandler(store UpdatesStore) *JSONHandler[domain.PaymentConfirmation] {
+	return NewJSONHandler(store.SavePaymentConfirmation,
+		func(c domain.PaymentConfirmation) string { return c.OrderUID })
+}
+
+// NewDeliveryStatusHandler изменения статуса доставки
+func NewDeliveryStatusHandler(store UpdatesStore) *JSONHandler[domain.DeliveryStatusUpdate] {
+	return NewJSONHandler(store.SaveDeliveryStatus,
+		func(u domain.DeliveryStatusUpdate) string { return u.OrderUID })
+}
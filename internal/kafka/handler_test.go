@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpdates struct {
+	mu       sync.Mutex
+	payments []domain.PaymentConfirmation
+}
+
+func (f *fakeUpdates) SavePaymentConfirmation(_ context.Context, c domain.PaymentConfirmation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.payments = append(f.payments, c)
+	return nil
+}
+
+func (f *fakeUpdates) SaveDeliveryStatus(context.Context, domain.DeliveryStatusUpdate) error {
+	return nil
+}
+
+func TestKafkaConsumer_TopicHandlers(t *testing.T) {
+	store := &fakeUpdates{}
+	handlers := orderHandlers(&fakeDB{})
+	handlers.Register("payments", NewPaymentHandler(store))
+	assert.Equal(t, []string{"orders", "payments"}, handlers.Topics())
+
+	dlq := &recordingDLQ{}
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, handlers, dlq, nil, nil)
+
+	claim := &fakeClaim{topic: "payments", messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "payments", Offset: 0, Value: []byte(
+		`{"order_uid":"a","transaction":"a","status":"confirmed","amount":1817,"currency":"USD","confirmed_at":"2025-10-01T12:00:00Z"}`)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "payments", Offset: 1, Value: []byte(
+		`{"order_uid":"b","transaction":"b","status":"pending","currency":"USD","confirmed_at":"2025-10-01T12:00:00Z"}`)}
+	close(claim.messages)
+
+	sess := &fakeSession{ctx: context.Background()}
+	require.NoError(t, kc.ConsumeClaim(sess, claim))
+
+	require.Len(t, store.payments, 1)
+	assert.Equal(t, time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC), store.payments[0].ConfirmedAt)
+	require.Len(t, dlq.failures, 1)
+	assert.Equal(t, StageValidate, dlq.failures[0].Stage)
+	assert.Equal(t, "Status", dlq.failures[0].Violations[0].Field)
+	assert.Equal(t, []int64{0, 1}, sess.marked)
+
+	unknown := &fakeClaim{topic: "refunds", messages: make(chan *sarama.ConsumerMessage)}
+	assert.ErrorContains(t, kc.ConsumeClaim(sess, unknown), "no handler for topic refunds")
+}
+
+func TestHandlers_Ingest(t *testing.T) {
+	store := &fakeUpdates{}
+	db := &fakeDB{}
+	handlers := orderHandlers(db)
+	handlers.Register("payments", NewPaymentHandler(store))
+	ctx := context.Background()
+
+	payment := []byte(`{"order_uid":"a","transaction":"a","status":"confirmed","amount":1817,"currency":"USD","confirmed_at":"2025-10-01T12:00:00Z"}`)
+	id, err := handlers.Ingest(ctx, "payments", nil, payment)
+	require.NoError(t, err)
+	assert.Zero(t, id)
+	require.Len(t, store.payments, 1)
+
+	// сообщение из топика повторов обрабатывается обработчиком исходного топика
+	_, err = handlers.Ingest(ctx, "payments.retry.10s", map[string]string{HeaderRetryOrigin: "payments/0/7"}, payment)
+	require.NoError(t, err)
+	assert.Len(t, store.payments, 2)
+
+	id, err = handlers.Ingest(ctx, "orders", nil, orderMessage(t, "o", 0).Value)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, []string{"o"}, db.stored)
+
+	_, err = handlers.Ingest(ctx, "refunds", nil, payment)
+	var failure *Failure
+	require.ErrorAs(t, err, &failure)
+	assert.Equal(t, StageDecode, failure.Stage)
+}
+
+func TestRetryScheduler_TiersPerTopic(t *testing.T) {
+	retries := NewRetryScheduler(mocks.NewSyncProducer(t, nil), []string{"orders", "payments"}, []time.Duration{10 * time.Second, time.Minute})
+
+	assert.Equal(t, []string{"orders.retry.10s", "orders.retry.1m", "payments.retry.10s", "payments.retry.1m"}, retries.Topics())
+	tier, ok := retries.Tier("payments.retry.1m")
+	require.True(t, ok)
+	assert.Equal(t, "payments", tier.Source)
+	assert.Equal(t, time.Minute, tier.Delay)
+}
@@ -0,0 +1,125 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/health"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Виды повторов для метрики kafka_consumer_retries_total
+const (
+	retryInline = "inline"
+	retryTopic  = "topic"
+)
+
+var (
+	highWaterMarkDesc = prometheus.NewDesc("kafka_consumer_high_water_mark",
+		"Offset of the next message to be written to the partition.",
+		[]string{"topic", "partition"}, nil)
+	offsetDesc = prometheus.NewDesc("kafka_consumer_offset",
+		"Offset of the next partition message to be processed.",
+		[]string{"topic", "partition"}, nil)
+	lagDesc = prometheus.NewDesc("kafka_consumer_lag",
+		"Number of partition messages not processed yet.",
+		[]string{"topic", "partition"}, nil)
+)
+
+// Metrics метрики чтения Kafka. Позиции партиций считываются в момент сбора,
+// остальные метрики накапливаются при обработке сообщений.
+type Metrics struct {
+	partitions *partitions
+	processed  *prometheus.CounterVec
+	failed     *prometheus.CounterVec
+	retries    *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+}
+
+func newMetrics(p *partitions) *Metrics {
+	return &Metrics{
+		partitions: p,
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kafka_consumer_messages_processed_total",
+			Help: "Messages whose processing finished and whose offset was marked.",
+		}, []string{"topic"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kafka_consumer_messages_failed_total",
+			Help: "Messages that failed a processing stage (decode, validate, persist).",
+		}, []string{"topic", "stage"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kafka_consumer_retries_total",
+			Help: "Persist retries: inline with backoff or scheduled to a retry topic.",
+		}, []string{"topic", "kind"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kafka_consumer_processing_seconds",
+			Help:    "Time from receiving a message until it is stored or dead-lettered.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"topic"}),
+	}
+}
+
+// Describe реализует prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- highWaterMarkDesc
+	ch <- offsetDesc
+	ch <- lagDesc
+	m.processed.Describe(ch)
+	m.failed.Describe(ch)
+	m.retries.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect реализует prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, ps := range m.partitions.states() {
+		partition := strconv.Itoa(int(ps.Partition))
+		ch <- prometheus.MustNewConstMetric(highWaterMarkDesc, prometheus.GaugeValue, float64(ps.HighWaterMark), ps.Topic, partition)
+		ch <- prometheus.MustNewConstMetric(offsetDesc, prometheus.GaugeValue, float64(ps.Offset), ps.Topic, partition)
+		ch <- prometheus.MustNewConstMetric(lagDesc, prometheus.GaugeValue, float64(ps.Lag), ps.Topic, partition)
+	}
+	m.processed.Collect(ch)
+	m.failed.Collect(ch)
+	m.retries.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+func (m *Metrics) observe(topic string, start time.Time) {
+	m.latency.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+}
+
+// Metrics метрики консьюмера для регистрации в prometheus
+func (kc *KafkaConsumer) Metrics() *Metrics {
+	return kc.metrics
+}
+
+// Check реализует health.Checker. Отставание не делает сервис
+// неработоспособным, поэтому при превышении порога статус degraded.
+func (kc *KafkaConsumer) Check(_ context.Context) health.Result {
+	var total, maxLag int64
+	var lagging []string
+	states := kc.partitions.states()
+	for _, ps := range states {
+		total += ps.Lag
+		maxLag = max(maxLag, ps.Lag)
+		if threshold := kc.cfg.Kafka.LagThreshold; threshold > 0 && ps.Lag > int64(threshold) {
+			lagging = append(lagging, ps.Topic+"/"+strconv.Itoa(int(ps.Partition)))
+		}
+	}
+
+	res := health.Result{
+		Status: health.StatusUp,
+		Details: map[string]any{
+			"partitions": len(states),
+			"total_lag":  total,
+			"max_lag":    maxLag,
+			"threshold":  kc.cfg.Kafka.LagThreshold,
+		},
+	}
+	if len(lagging) > 0 {
+		res.Status = health.StatusDegraded
+		res.Details["lagging"] = lagging
+	}
+	return res
+}
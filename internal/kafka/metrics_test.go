@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/config"
+	"l0/internal/health"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaConsumer_Metrics(t *testing.T) {
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	for i, msg := range []*sarama.ConsumerMessage{
+		orderMessage(t, "a", 0),
+		{Value: []byte("not json"), Offset: 1},
+		orderMessage(t, "b", 2),
+	} {
+		msg.Topic = "orders"
+		msg.Offset = int64(i)
+		claim.messages <- msg
+	}
+	close(claim.messages)
+
+	require.NoError(t, kc.ConsumeClaim(&fakeSession{ctx: context.Background()}, claim))
+
+	m := kc.Metrics()
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.processed.WithLabelValues("orders")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.failed.WithLabelValues("orders", StageDecode)))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.latency))
+}
+
+func TestKafkaConsumer_CheckLag(t *testing.T) {
+	cfg := config.Config{Kafka: config.KafkaConfig{LagThreshold: 5}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 10)}
+	sess, release := kc.claim(&fakeSession{ctx: context.Background()}, claim)
+	defer release()
+
+	res := kc.Check(context.Background())
+	assert.Equal(t, health.StatusDegraded, res.Status)
+	assert.Equal(t, int64(10), res.Details["max_lag"])
+
+	expected := `
+# HELP kafka_consumer_lag Number of partition messages not processed yet.
+# TYPE kafka_consumer_lag gauge
+kafka_consumer_lag{partition="0",topic="orders"} 3
+`
+	sess.MarkMessage(&sarama.ConsumerMessage{Topic: "orders", Offset: 6}, "")
+	assert.NoError(t, testutil.CollectAndCompare(kc.Metrics(), strings.NewReader(expected), "kafka_consumer_lag"))
+	assert.Equal(t, health.StatusUp, kc.Check(context.Background()).Status)
+}
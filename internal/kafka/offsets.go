@@ -0,0 +1,132 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"l0/internal/domain"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// offsetAdvanceStep через сколько отмеченных смещений позиция партиции в БД
+// сдвигается вперёд, а отметки сообщений до неё удаляются
+const offsetAdvanceStep = 1000
+
+// offsetAdvanceTimeout ограничивает сдвиг позиции партиции в БД
+const offsetAdvanceTimeout = 5 * time.Second
+
+// OffsetStore позиции партиций в БД. Каждое сообщение отмечается
+// обработанным в транзакции с его данными, поэтому после сбоя между
+// транзакцией и фиксацией смещения в Kafka сообщение не применяется
+// повторно, в том числе при параллельных обработчиках. Позиция партиции —
+// непрерывно обработанный префикс, отметки сообщений до неё не хранятся.
+type OffsetStore interface {
+	// Offsets позиции партиций топика для группы и обработанные сообщения
+	// после них
+	Offsets(ctx context.Context, group, topic string) (map[int32]domain.PartitionOffsets, error)
+	// SaveOffset отмечает сообщение обработанным без данных
+	SaveOffset(ctx context.Context, o domain.MessageOffset) error
+	// AdvanceOffset сдвигает позицию партиции вперёд до next
+	AdvanceOffset(ctx context.Context, group, topic string, partition int32, next int64) error
+	// ResetOffset переносит позицию партиции на next и снимает отметки её
+	// сообщений
+	ResetOffset(ctx context.Context, group, topic string, partition int32, next int64) error
+}
+
+// restoreOffsets начинает чтение назначенных партиций с позиций из БД:
+// смещения в Kafka могут отставать от неё
+func (kc *KafkaConsumer) restoreOffsets(sess sarama.ConsumerGroupSession) error {
+	if kc.offsets == nil {
+		return nil
+	}
+	group := kc.cfg.Kafka.ConsumerGroup
+	restored := make(map[partitionKey]restoredPartition)
+	for topic, parts := range sess.Claims() {
+		stored, err := kc.offsets.Offsets(sess.Context(), group, topic)
+		if err != nil {
+			return fmt.Errorf("load offsets of %s: %w", topic, err)
+		}
+		for _, partition := range parts {
+			po, ok := stored[partition]
+			if !ok {
+				continue
+			}
+			r := restoredPartition{next: po.Next, processed: make(map[int64]bool, len(po.Processed))}
+			for _, offset := range po.Processed {
+				r.processed[offset] = true
+			}
+			if po.Next >= 0 {
+				sess.ResetOffset(topic, partition, po.Next, "")
+				sess.MarkOffset(topic, partition, po.Next, "")
+			}
+			restored[partitionKey{topic, partition}] = r
+		}
+	}
+
+	p := kc.partitions
+	p.mu.Lock()
+	p.restored = restored
+	p.mu.Unlock()
+	kc.logger.Info("partition offsets restored from database", "partitions", len(restored))
+	return nil
+}
+
+// processed сообщение уже отмечено в БД как обработанное
+func (kc *KafkaConsumer) processed(msg *sarama.ConsumerMessage) bool {
+	if kc.offsets == nil {
+		return false
+	}
+	p := kc.partitions
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.restored[partitionKey{msg.Topic, msg.Partition}]
+	return ok && (msg.Offset < r.next || r.processed[msg.Offset])
+}
+
+func (kc *KafkaConsumer) messageOffset(msg *sarama.ConsumerMessage) domain.MessageOffset {
+	return domain.MessageOffset{
+		Group:     kc.cfg.Kafka.ConsumerGroup,
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}
+}
+
+// withOffset ctx, в котором обработчик отмечает msgs обработанными в своей
+// транзакции
+func (kc *KafkaConsumer) withOffset(ctx context.Context, msgs ...*sarama.ConsumerMessage) context.Context {
+	if kc.offsets == nil {
+		return ctx
+	}
+	offsets := make([]domain.MessageOffset, len(msgs))
+	for i, msg := range msgs {
+		offsets[i] = kc.messageOffset(msg)
+	}
+	return domain.WithMessageOffsets(ctx, offsets...)
+}
+
+// saveOffset отмечает обработанным сообщение, ушедшее в DLQ или на повтор
+func (kc *KafkaConsumer) saveOffset(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	if kc.offsets == nil {
+		return nil
+	}
+	if err := kc.offsets.SaveOffset(ctx, kc.messageOffset(msg)); err != nil {
+		return fmt.Errorf("save offset: %w", err)
+	}
+	return nil
+}
+
+// advanceOffset сдвигает позицию партиции в БД до next. Ошибка не
+// критична: отметки сообщений остаются, позиция сдвинется в следующий раз.
+func (kc *KafkaConsumer) advanceOffset(key partitionKey, next int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), offsetAdvanceTimeout)
+	defer cancel()
+	if err := kc.offsets.AdvanceOffset(ctx, kc.cfg.Kafka.ConsumerGroup, key.topic, key.partition, next); err != nil {
+		kc.logger.Warn("failed to advance partition offset",
+			"topic", key.topic,
+			"partition", key.partition,
+			"offset", next,
+			"error", err.Error())
+	}
+}
@@ -0,0 +1,223 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOffsets позиции партиций топика orders и отметки сообщений после них
+type memoryOffsets struct {
+	mu        sync.Mutex
+	next      map[int32]int64
+	processed map[int32]map[int64]bool
+}
+
+func newMemoryOffsets(next map[int32]int64) *memoryOffsets {
+	return &memoryOffsets{next: next, processed: map[int32]map[int64]bool{}}
+}
+
+func (m *memoryOffsets) Offsets(_ context.Context, _, topic string) (map[int32]domain.PartitionOffsets, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[int32]domain.PartitionOffsets)
+	if topic != "orders" {
+		return out, nil
+	}
+	for p, next := range m.next {
+		out[p] = domain.PartitionOffsets{Next: next}
+	}
+	for p, offsets := range m.processed {
+		po, ok := out[p]
+		if !ok {
+			po.Next = -1
+		}
+		for _, off := range slices.Sorted(maps.Keys(offsets)) {
+			if off >= po.Next {
+				po.Processed = append(po.Processed, off)
+			}
+		}
+		out[p] = po
+	}
+	return out, nil
+}
+
+func (m *memoryOffsets) SaveOffset(_ context.Context, o domain.MessageOffset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.processed[o.Partition] == nil {
+		m.processed[o.Partition] = map[int64]bool{}
+	}
+	m.processed[o.Partition][o.Offset] = true
+	return nil
+}
+
+func (m *memoryOffsets) AdvanceOffset(_ context.Context, _, _ string, partition int32, next int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cur, ok := m.next[partition]; !ok || next > cur {
+		m.next[partition] = next
+	}
+	for off := range m.processed[partition] {
+		if off < m.next[partition] {
+			delete(m.processed[partition], off)
+		}
+	}
+	return nil
+}
+
+func (m *memoryOffsets) ResetOffset(_ context.Context, _, _ string, partition int32, next int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next[partition] = next
+	delete(m.processed, partition)
+	return nil
+}
+
+// done отметки сообщений партиции после её позиции
+func (m *memoryOffsets) done(partition int32) []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Sorted(maps.Keys(m.processed[partition]))
+}
+
+// offsetDB сохраняет заказы и отметки сообщений из ctx, как одна транзакция
+type offsetDB struct {
+	fakeDB
+	offsets *memoryOffsets
+	// onBatch вызывается перед пакетной транзакцией
+	onBatch func()
+}
+
+func (db *offsetDB) save(ctx context.Context) {
+	for _, o := range domain.MessageOffsetsFrom(ctx) {
+		_ = db.offsets.SaveOffset(ctx, o)
+	}
+}
+
+func (db *offsetDB) CreateOrder(ctx context.Context, order domain.Order) (int, error) {
+	id, err := db.fakeDB.CreateOrder(ctx, order)
+	if err == nil {
+		db.save(ctx)
+	}
+	return id, err
+}
+
+func (db *offsetDB) CreateOrders(ctx context.Context, orders []domain.Order) ([]int, error) {
+	if db.onBatch != nil {
+		db.onBatch()
+	}
+	ids, err := db.fakeDB.CreateOrders(ctx, orders)
+	if err == nil {
+		db.save(ctx)
+	}
+	return ids, err
+}
+
+func TestKafkaConsumer_OffsetsInDatabase(t *testing.T) {
+	offsets := newMemoryOffsets(map[int32]int64{0: 5})
+	db := &offsetDB{offsets: offsets}
+	dlq := &recordingDLQ{}
+	cfg := config.Config{Kafka: config.KafkaConfig{ConsumerGroup: "app"}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), dlq, nil, offsets)
+	ctx := context.Background()
+
+	sess := &seekSession{fakeSession: fakeSession{ctx: ctx}, reset: map[int32]int64{}, marked: map[int32]int64{}}
+	require.NoError(t, kc.Setup(sess))
+	assert.Equal(t, int64(5), sess.reset[0], "database position wins over kafka")
+	assert.Equal(t, int64(5), sess.marked[0])
+
+	message := func(uid string, offset int64) *sarama.ConsumerMessage {
+		msg := orderMessage(t, uid, offset)
+		msg.Topic = "orders"
+		return msg
+	}
+
+	// сообщение до позиции из БД уже сохранено
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), message("a", 4)))
+	assert.Empty(t, db.stored)
+
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), message("b", 5)))
+	assert.Equal(t, []string{"b"}, db.stored)
+	assert.Equal(t, []int64{5}, offsets.done(0))
+
+	// сообщение из DLQ тоже считается обработанным
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), &sarama.ConsumerMessage{Topic: "orders", Offset: 6, Value: []byte("not json")}))
+	require.Len(t, dlq.failures, 1)
+	assert.Equal(t, []int64{5, 6}, offsets.done(0))
+
+	// перемотка назад переносит позицию и в БД и снимает отметки
+	kc.partitions.seeks[partitionKey{"orders", 0}] = 3
+	require.NoError(t, kc.Setup(sess))
+	assert.Equal(t, int64(3), offsets.next[0])
+	assert.Empty(t, offsets.done(0))
+	assert.Equal(t, int64(3), sess.reset[0])
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), message("a", 4)))
+	assert.Equal(t, []string{"b", "a"}, db.stored)
+}
+
+func TestKafkaConsumer_ProcessBatch_OffsetsPerMessage(t *testing.T) {
+	offsets := newMemoryOffsets(map[int32]int64{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// пакетная транзакция не удалась, а сессия завершилась до вставок по одному
+	db := &offsetDB{fakeDB: fakeDB{reject: map[string]bool{"a": true}}, offsets: offsets, onBatch: cancel}
+	dlq := &recordingDLQ{}
+	cfg := config.Config{Kafka: config.KafkaConfig{ConsumerGroup: "app", BatchSize: 10}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), dlq, nil, offsets)
+
+	batch := func() []*sarama.ConsumerMessage {
+		valid := orderMessage(t, "a", 1)
+		valid.Topic = "orders"
+		return []*sarama.ConsumerMessage{valid, {Topic: "orders", Offset: 2, Value: []byte("not json")}}
+	}
+
+	require.Error(t, kc.processBatch(ctx, ordersHandler(kc), batch()))
+	require.Len(t, dlq.failures, 1)
+	assert.Empty(t, db.stored)
+	assert.Equal(t, []int64{2}, offsets.done(0), "unsaved order must not be marked")
+
+	// в новой сессии сохраняется только заказ, сообщение из DLQ пропускается
+	sess := &seekSession{fakeSession: fakeSession{ctx: context.Background()}, reset: map[int32]int64{}, marked: map[int32]int64{}}
+	require.NoError(t, kc.Setup(sess))
+	db.reject, db.onBatch = nil, nil
+	require.NoError(t, kc.processBatch(context.Background(), ordersHandler(kc), batch()))
+	assert.Equal(t, []string{"a"}, db.stored)
+	assert.Len(t, dlq.failures, 1)
+	assert.Equal(t, []int64{1, 2}, offsets.done(0))
+}
+
+func TestKafkaConsumer_ParallelOffsetsInDatabase(t *testing.T) {
+	// 5 обработано раньше 3 и 4, позиция партиции стоит на 3
+	offsets := newMemoryOffsets(map[int32]int64{0: 3})
+	_ = offsets.SaveOffset(context.Background(), domain.MessageOffset{Partition: 0, Offset: 5})
+	db := &offsetDB{offsets: offsets}
+	cfg := config.Config{Kafka: config.KafkaConfig{ConsumerGroup: "app", Workers: 4}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), &recordingDLQ{}, nil, offsets)
+
+	sess := &seekSession{fakeSession: fakeSession{ctx: context.Background()}, reset: map[int32]int64{}, marked: map[int32]int64{}}
+	require.NoError(t, kc.Setup(sess))
+	assert.Equal(t, int64(3), sess.reset[0])
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 4)}
+	for i, uid := range []string{"c", "d", "e", "f"} {
+		msg := orderMessage(t, uid, int64(3+i))
+		msg.Topic = "orders"
+		claim.messages <- msg
+	}
+	close(claim.messages)
+	require.NoError(t, kc.ConsumeClaim(sess, claim))
+
+	assert.ElementsMatch(t, []string{"c", "d", "f"}, db.stored, "message 5 is already processed")
+	assert.Equal(t, int64(7), offsets.next[0], "position advances when the claim is released")
+	assert.Empty(t, offsets.done(0))
+}
@@ -0,0 +1,194 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// workerQueueSize сколько сообщений может ждать в очереди одного обработчика
+const workerQueueSize = 16
+
+// parallelJob сообщение партиции, разобранное диспетчером
+type parallelJob struct {
+	msg      *sarama.ConsumerMessage
+	value    Message
+	failure  *Failure
+	received time.Time
+}
+
+// consumeParallel обрабатывает сообщения партиции KAFKA_WORKERS обработчиками.
+// Сообщения с одним ключом попадают к одному обработчику и обрабатываются по
+// порядку. Смещение отмечается только когда обработаны все предыдущие
+// сообщения партиции, поэтому фиксация не пропускает необработанных.
+func (kc *KafkaConsumer) consumeParallel(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, handler MessageHandler) error {
+	parent := sess.Context()
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+		failed   *sarama.ConsumerMessage
+	)
+	fail := func(msg *sarama.ConsumerMessage, err error) {
+		errOnce.Do(func() {
+			firstErr, failed = err, msg
+			cancel()
+		})
+	}
+
+	tracker := newOffsetTracker()
+	queues := make([]chan parallelJob, kc.cfg.Kafka.Workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan parallelJob, workerQueueSize)
+		wg.Add(1)
+		go func(jobs <-chan parallelJob) {
+			defer wg.Done()
+			defer kc.recoverPanic(nil)
+			for job := range jobs {
+				// после ошибки очередь только вычерпывается: эти сообщения
+				// не отмечены и будут прочитаны повторно
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := kc.processJob(ctx, handler, job); err != nil {
+					fail(job.msg, err)
+					continue
+				}
+				tracker.complete(job.msg, func(m *sarama.ConsumerMessage) { sess.MarkMessage(m, "") })
+			}
+		}(queues[i])
+	}
+
+	finish := func() error {
+		for _, q := range queues {
+			close(q)
+		}
+		wg.Wait()
+		if firstErr == nil || parent.Err() != nil {
+			return nil
+		}
+		kc.logger.Error("failed to process message, restarting session",
+			"partition", failed.Partition,
+			"offset", failed.Offset,
+			"error", firstErr.Error())
+		return kc.failSession(failed, firstErr)
+	}
+
+	next := 0
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				kc.logger.Info("message channel closed", "partition", claim.Partition())
+				return finish()
+			}
+
+			job := parallelJob{msg: msg, received: time.Now()}
+			job.value, job.failure = handler.Decode(ctx, messageHeaders(msg), msg.Value)
+
+			var q chan parallelJob
+			if key := kc.orderingKey(job); key != "" {
+				h := fnv.New32a()
+				_, _ = h.Write([]byte(key))
+				q = queues[h.Sum32()%uint32(len(queues))]
+			} else {
+				q = queues[next%len(queues)]
+				next++
+			}
+
+			tracker.add(msg)
+			select {
+			case q <- job:
+			case <-ctx.Done():
+				return finish()
+			}
+
+		case <-ctx.Done():
+			return finish()
+		}
+	}
+}
+
+// processJob применяет сообщение или отправляет его в DLQ; ошибка
+// означает, что смещение отмечать нельзя
+func (kc *KafkaConsumer) processJob(ctx context.Context, handler MessageHandler, job parallelJob) error {
+	if kc.processed(job.msg) {
+		return nil
+	}
+	defer kc.metrics.observe(job.msg.Topic, job.received)
+	failure := job.failure
+	if failure == nil {
+		failure = kc.apply(ctx, handler, job.msg, job.value)
+	}
+	if failure == nil {
+		return nil
+	}
+	return kc.routeFailure(ctx, job.msg, failure)
+}
+
+// orderingKey ключ, порядок сообщений с которым сохраняется. Пустой ключ —
+// сообщение можно обработать любым обработчиком.
+func (kc *KafkaConsumer) orderingKey(job parallelJob) string {
+	by := kc.cfg.Kafka.OrderingKey
+	if job.failure == nil && by != "" && by != "key" {
+		return job.value.OrderingKey(by)
+	}
+	if len(job.msg.Key) > 0 {
+		return string(job.msg.Key)
+	}
+	if job.failure == nil {
+		return job.value.OrderingKey("")
+	}
+	return ""
+}
+
+// offsetTracker отслеживает сообщения партиции в порядке чтения и отмечает
+// их только непрерывным префиксом обработанных
+type offsetTracker struct {
+	mu       sync.Mutex
+	pending  []*trackedMessage
+	byOffset map[int64]*trackedMessage
+}
+
+type trackedMessage struct {
+	msg  *sarama.ConsumerMessage
+	done bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{byOffset: make(map[int64]*trackedMessage)}
+}
+
+// add регистрирует прочитанное сообщение
+func (t *offsetTracker) add(msg *sarama.ConsumerMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := &trackedMessage{msg: msg}
+	t.pending = append(t.pending, m)
+	t.byOffset[msg.Offset] = m
+}
+
+// complete отмечает сообщение обработанным и вызывает mark для каждого
+// сообщения, ставшего частью непрерывного обработанного префикса
+func (t *offsetTracker) complete(msg *sarama.ConsumerMessage, mark func(*sarama.ConsumerMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m, ok := t.byOffset[msg.Offset]
+	if !ok {
+		return
+	}
+	m.done = true
+	for len(t.pending) > 0 && t.pending[0].done {
+		head := t.pending[0]
+		mark(head.msg)
+		delete(t.byOffset, head.msg.Offset)
+		t.pending[0] = nil
+		t.pending = t.pending[1:]
+	}
+}
@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"l0/internal/config"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	topic    string
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string {
+	if c.topic == "" {
+		return "orders"
+	}
+	return c.topic
+}
+
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return int64(cap(c.messages)) }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestOffsetTracker_MarksContiguousPrefix(t *testing.T) {
+	tracker := newOffsetTracker()
+	msgs := make([]*sarama.ConsumerMessage, 4)
+	for i := range msgs {
+		msgs[i] = &sarama.ConsumerMessage{Offset: int64(10 + i)}
+		tracker.add(msgs[i])
+	}
+
+	var marked []int64
+	mark := func(m *sarama.ConsumerMessage) { marked = append(marked, m.Offset) }
+
+	tracker.complete(msgs[2], mark)
+	tracker.complete(msgs[1], mark)
+	assert.Empty(t, marked, "offset 10 is still in progress")
+
+	tracker.complete(msgs[0], mark)
+	assert.Equal(t, []int64{10, 11, 12}, marked)
+
+	tracker.complete(msgs[3], mark)
+	assert.Equal(t, []int64{10, 11, 12, 13}, marked)
+}
+
+func TestKafkaConsumer_ConsumeParallel_KeepsKeyOrder(t *testing.T) {
+	db := &fakeDB{}
+	cfg := config.Config{Kafka: config.KafkaConfig{Workers: 4}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), &recordingDLQ{}, nil, nil)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 60)}
+	for i := range 60 {
+		key := fmt.Sprintf("customer%d", i%5)
+		msg := orderMessage(t, fmt.Sprintf("%s-%02d", key, i), int64(i))
+		msg.Key = []byte(key)
+		claim.messages <- msg
+	}
+	close(claim.messages)
+
+	sess := &fakeSession{ctx: context.Background()}
+	require.NoError(t, kc.ConsumeClaim(sess, claim))
+
+	require.Len(t, db.stored, 60)
+	last := map[string]string{}
+	for _, uid := range db.stored {
+		key := uid[:strings.IndexByte(uid, '-')]
+		assert.Less(t, last[key], uid, "orders of %s are stored in offset order", key)
+		last[key] = uid
+	}
+
+	require.Len(t, sess.marked, 60)
+	for i, off := range sess.marked {
+		assert.Equal(t, int64(i), off, "offsets are marked in order")
+	}
+}
+
+func TestKafkaConsumer_ConsumeParallel_DoesNotSkipFailed(t *testing.T) {
+	db := &fakeDB{reject: map[string]bool{"a-03": true}}
+	dlq := &recordingDLQ{err: errors.New("broker unavailable")}
+	cfg := config.Config{Kafka: config.KafkaConfig{Workers: 3}}
+	kc := NewKafkaConsumer(cfg, slog.Default(), nil, orderHandlers(db), dlq, nil, nil)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 10)}
+	for i := range 10 {
+		msg := orderMessage(t, fmt.Sprintf("%c-%02d", 'a'+i%3, i), int64(i))
+		claim.messages <- msg
+	}
+	close(claim.messages)
+
+	sess := &fakeSession{ctx: context.Background()}
+	assert.Error(t, kc.ConsumeClaim(sess, claim))
+	for _, off := range sess.marked {
+		assert.Less(t, off, int64(3), "nothing after the failed offset is marked")
+	}
+}
@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"l0/internal/domain"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Pipeline разбор, проверка и сохранение заказа. Общий путь для сообщений
+// из Kafka и повторной обработки сообщений из карантина.
+type Pipeline struct {
+	db        DB
+	decoders  *Decoders
+	rules     OrderRules
+	validator *validator.Validate
+}
+
+// OrderRules настраиваемые правила по Entry, реализуется rules.Engine
+type OrderRules interface {
+	Check(order domain.Order) error
+}
+
+// NewPipeline decoders может быть nil, тогда принимается только JSON;
+// rules может быть nil, тогда проверяются только встроенные правила
+func NewPipeline(db DB, decoders *Decoders, rules OrderRules) *Pipeline {
+	if decoders == nil {
+		decoders = jsonDecoders()
+	}
+	return &Pipeline{
+		db:        db,
+		decoders:  decoders,
+		rules:     rules,
+		validator: validator.New(),
+	}
+}
+
+// Decode разбирает тело в формате и версии из заголовков и проверяет заказ
+func (p *Pipeline) Decode(ctx context.Context, headers map[string]string, value []byte) (domain.Order, *Failure) {
+	version := 0
+	if v := headers[HeaderSchemaVersion]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return domain.Order{}, &Failure{Stage: StageDecode, Err: fmt.Errorf("invalid %s header %q", HeaderSchemaVersion, v), Attempts: 1}
+		}
+		version = n
+	}
+
+	order, err := p.decoders.Decode(ctx, headers[HeaderContentType], version, value)
+	if err != nil {
+		return domain.Order{}, &Failure{Stage: StageDecode, Err: err, Attempts: 1}
+	}
+	if err := p.validator.Struct(order); err != nil {
+		return domain.Order{}, &Failure{Stage: StageValidate, Err: err, Attempts: 1, Violations: violations(err)}
+	}
+	if err := domain.ValidateOrder(order); err != nil {
+		return domain.Order{}, &Failure{Stage: StageValidate, Err: err, Attempts: 1, Violations: violations(err)}
+	}
+	if p.rules != nil {
+		if err := p.rules.Check(order); err != nil {
+			return domain.Order{}, &Failure{Stage: StageValidate, Err: err, Attempts: 1, Violations: violations(err)}
+		}
+	}
+	return order, nil
+}
+
+// Ingest обрабатывает сообщение за одну попытку. Ошибка обработки
+// возвращается как *Failure.
+func (p *Pipeline) Ingest(ctx context.Context, headers map[string]string, value []byte) (int, error) {
+	order, failure := p.Decode(ctx, headers, value)
+	if failure != nil {
+		return 0, failure
+	}
+	id, err := p.db.CreateOrder(ctx, order)
+	if err != nil {
+		return 0, &Failure{Stage: StagePersist, Err: fmt.Errorf("store order %s: %w", order.OrderUID, err), Attempts: 1}
+	}
+	return id, nil
+}
+
+// violations переводит ошибки validator, domain.ValidateOrder и правил в список нарушений
+func violations(err error) []domain.Violation {
+	var orderErr *domain.ValidationError
+	if errors.As(err, &orderErr) {
+		return orderErr.Violations
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	out := make([]domain.Violation, 0, len(verrs))
+	for _, fe := range verrs {
+		field := fe.Namespace()
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+		out = append(out, domain.Violation{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: fe.Error(),
+		})
+	}
+	return out
+}
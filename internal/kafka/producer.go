@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"l0/internal/domain"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// Заголовки исходящих событий
+const (
+	HeaderEventType    = "event-type"
+	HeaderEventVersion = "event-version"
+	HeaderEventID      = "event-id"
+)
+
+// EventProducer публикует доменные события в топик асинхронно. Ошибки
+// доставки логируются; Close дожидается отправки всех принятых событий.
+type EventProducer struct {
+	producer sarama.AsyncProducer
+	topic    string
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+func NewEventProducer(producer sarama.AsyncProducer, topic string, logger *slog.Logger) *EventProducer {
+	p := &EventProducer{
+		producer: producer,
+		topic:    topic,
+		logger:   logger,
+	}
+	p.wg.Add(2)
+	go p.drainSuccesses()
+	go p.drainErrors()
+	return p
+}
+
+// Publish ставит событие в очередь отправки
+func (p *EventProducer) Publish(ctx context.Context, ev domain.Event) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event %s: %w", ev.Type, err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(ev.Key),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			header(HeaderEventType, ev.Type),
+			header(HeaderEventVersion, strconv.Itoa(ev.Version)),
+			header(HeaderEventID, ev.ID),
+		},
+		Metadata: ev,
+	}
+
+	select {
+	case p.producer.Input() <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close отправляет накопленные события и закрывает продюсер
+func (p *EventProducer) Close() error {
+	err := p.producer.Close()
+	p.wg.Wait()
+	return err
+}
+
+func (p *EventProducer) drainSuccesses() {
+	defer p.wg.Done()
+	for msg := range p.producer.Successes() {
+		if ev, ok := msg.Metadata.(domain.Event); ok {
+			p.logger.Debug("event published", "type", ev.Type, "id", ev.ID, "partition", msg.Partition, "offset", msg.Offset)
+		}
+	}
+}
+
+func (p *EventProducer) drainErrors() {
+	defer p.wg.Done()
+	for perr := range p.producer.Errors() {
+		attrs := []any{"error", perr.Err}
+		if ev, ok := perr.Msg.Metadata.(domain.Event); ok {
+			attrs = append(attrs, "type", ev.Type, "id", ev.ID, "key", ev.Key)
+		}
+		p.logger.Error("failed to publish event", attrs...)
+	}
+}
@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"log/slog"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEventProducerConfig_Idempotent(t *testing.T) {
+	sc, err := NewEventProducerConfig(config.KafkaConfig{EventsAcks: "all", EventsIdempotent: true})
+	require.NoError(t, err)
+	assert.True(t, sc.Producer.Idempotent)
+	assert.Equal(t, sarama.WaitForAll, sc.Producer.RequiredAcks)
+}
+
+func TestEventProducer_Publish(t *testing.T) {
+	sc := mocks.NewTestConfig()
+	sc.Producer.Return.Successes = true
+	producer := mocks.NewAsyncProducer(t, sc)
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, "orders.events", msg.Topic)
+
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, "b563feb7b2b84b6test", string(key))
+
+		value, _ := msg.Value.Encode()
+		var ev struct {
+			Type    string `json:"type"`
+			Version int    `json:"version"`
+			Key     string `json:"key"`
+			Data    struct {
+				OrderID int `json:"order_id"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(value, &ev))
+		assert.Equal(t, domain.EventOrderCreated, ev.Type)
+		assert.Equal(t, domain.EventSchemaVersion, ev.Version)
+		assert.Equal(t, "b563feb7b2b84b6test", ev.Key)
+		assert.Equal(t, 7, ev.Data.OrderID)
+		return nil
+	})
+
+	p := NewEventProducer(producer, "orders.events", slog.Default())
+	ev := domain.NewOrderCreatedEvent(7, domain.Order{OrderUID: "b563feb7b2b84b6test"})
+	require.NoError(t, p.Publish(context.Background(), ev))
+	// Close дожидается подтверждения всех отправленных событий
+	require.NoError(t, p.Close())
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"context"
+	"l0/internal/domain"
+
+	"github.com/IBM/sarama"
+)
+
+// QuarantineStore хранилище необработанных сообщений
+type QuarantineStore interface {
+	Quarantine(ctx context.Context, m domain.QuarantinedMessage) (int64, error)
+}
+
+// QuarantineSink сохраняет необработанные сообщения в карантин, откуда их
+// можно исправить и обработать повторно
+type QuarantineSink struct {
+	store QuarantineStore
+}
+
+func NewQuarantineSink(store QuarantineStore) *QuarantineSink {
+	return &QuarantineSink{store: store}
+}
+
+func (q *QuarantineSink) Publish(ctx context.Context, msg *sarama.ConsumerMessage, failure Failure) error {
+	reason := ""
+	if failure.Err != nil {
+		reason = failure.Err.Error()
+	}
+
+	_, err := q.store.Quarantine(ctx, domain.QuarantinedMessage{
+		Topic:      msg.Topic,
+		Partition:  msg.Partition,
+		Offset:     msg.Offset,
+		Key:        string(msg.Key),
+		Payload:    string(msg.Value),
+		Headers:    messageHeaders(msg),
+		Stage:      failure.Stage,
+		Reason:     reason,
+		Violations: failure.Violations,
+		Attempts:   failure.Attempts,
+	})
+	return err
+}
@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Заголовки сообщения в топике повторов
+const (
+	HeaderRetryAttempt = "x-retry-attempt"
+	HeaderRetryDueAt   = "x-retry-due-at"
+	HeaderRetryError   = "x-retry-error"
+	// HeaderRetryOrigin исходные топик, партиция и смещение: "orders/2/17"
+	HeaderRetryOrigin = "x-retry-origin"
+)
+
+// RetryTier уровень повторов: сообщения из Topic обрабатываются обработчиком
+// топика Source не раньше, чем через Delay после попадания в Topic
+type RetryTier struct {
+	Topic  string
+	Source string
+	Delay  time.Duration
+	// level номер уровня, начиная с 0
+	level int
+}
+
+// RetryTopic имя топика повторов: orders.retry.10s, orders.retry.1m
+func RetryTopic(topic string, delay time.Duration) string {
+	return topic + ".retry." + delayName(delay)
+}
+
+func delayName(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	default:
+		return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+	}
+}
+
+// RetryScheduler пересылает сообщения, которые не удалось сохранить, в топики
+// повторов вместо повторов на месте, чтобы не задерживать партицию
+type RetryScheduler struct {
+	producer sarama.SyncProducer
+	sources  []string
+	tiers    map[string][]RetryTier
+	byTopic  map[string]RetryTier
+	now      func() time.Time
+}
+
+// NewRetryScheduler уровни повторов delays для каждого из топиков topics
+func NewRetryScheduler(producer sarama.SyncProducer, topics []string, delays []time.Duration) *RetryScheduler {
+	s := &RetryScheduler{
+		producer: producer,
+		sources:  topics,
+		tiers:    make(map[string][]RetryTier, len(topics)),
+		byTopic:  make(map[string]RetryTier, len(topics)*len(delays)),
+		now:      time.Now,
+	}
+	for _, topic := range topics {
+		for i, d := range delays {
+			tier := RetryTier{Topic: RetryTopic(topic, d), Source: topic, Delay: d, level: i}
+			s.tiers[topic] = append(s.tiers[topic], tier)
+			s.byTopic[tier.Topic] = tier
+		}
+	}
+	return s
+}
+
+// Topics топики повторов, на которые нужно подписаться
+func (s *RetryScheduler) Topics() []string {
+	var topics []string
+	for _, source := range s.sources {
+		for _, t := range s.tiers[source] {
+			topics = append(topics, t.Topic)
+		}
+	}
+	return topics
+}
+
+// Tier возвращает уровень повторов для топика
+func (s *RetryScheduler) Tier(topic string) (RetryTier, bool) {
+	tier, ok := s.byTopic[topic]
+	return tier, ok
+}
+
+// Schedule отправляет сообщение на следующий уровень повторов. Возвращает
+// false, если уровни закончились и сообщение пора отправить в DLQ.
+func (s *RetryScheduler) Schedule(_ context.Context, msg *sarama.ConsumerMessage, failure Failure) (bool, error) {
+	source, next := msg.Topic, 0
+	if tier, ok := s.byTopic[msg.Topic]; ok {
+		source, next = tier.Source, tier.level+1
+	}
+	if next >= len(s.tiers[source]) {
+		return false, nil
+	}
+	tier := s.tiers[source][next]
+
+	origin := headerValue(msg, HeaderRetryOrigin)
+	if origin == "" {
+		origin = fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+	}
+	errText := ""
+	if failure.Err != nil {
+		errText = failure.Err.Error()
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+4)
+	for _, h := range msg.Headers {
+		if h == nil {
+			continue
+		}
+		switch string(h.Key) {
+		case HeaderRetryAttempt, HeaderRetryDueAt, HeaderRetryError, HeaderRetryOrigin:
+			continue
+		}
+		headers = append(headers, *h)
+	}
+	headers = append(headers,
+		header(HeaderRetryAttempt, strconv.Itoa(RetryAttempt(msg)+1)),
+		header(HeaderRetryDueAt, strconv.FormatInt(s.now().Add(tier.Delay).UnixMilli(), 10)),
+		header(HeaderRetryError, errText),
+		header(HeaderRetryOrigin, origin),
+	)
+
+	out := &sarama.ProducerMessage{Topic: tier.Topic, Headers: headers}
+	if msg.Key != nil {
+		out.Key = sarama.ByteEncoder(msg.Key)
+	}
+	if msg.Value != nil {
+		out.Value = sarama.ByteEncoder(msg.Value)
+	}
+	if _, _, err := s.producer.SendMessage(out); err != nil {
+		return false, fmt.Errorf("publish to retry topic %s: %w", tier.Topic, err)
+	}
+	return true, nil
+}
+
+// RetryAttempt номер повтора сообщения, 0 — сообщение из основного топика
+func RetryAttempt(msg *sarama.ConsumerMessage) int {
+	n, _ := strconv.Atoi(headerValue(msg, HeaderRetryAttempt))
+	return n
+}
+
+// originTopic исходный топик сообщения: для сообщений из топиков повторов —
+// из заголовка x-retry-origin
+func originTopic(topic string, headers map[string]string) string {
+	if origin := headers[HeaderRetryOrigin]; origin != "" {
+		if i := strings.IndexByte(origin, '/'); i > 0 {
+			return origin[:i]
+		}
+	}
+	return topic
+}
+
+// retryDueAt когда сообщение из топика повторов можно обрабатывать
+func retryDueAt(msg *sarama.ConsumerMessage, tier RetryTier) time.Time {
+	if ms, err := strconv.ParseInt(headerValue(msg, HeaderRetryDueAt), 10, 64); err == nil {
+		return time.UnixMilli(ms)
+	}
+	return msg.Timestamp.Add(tier.Delay)
+}
+
+// messageHeaders заголовки сообщения; при повторе ключа побеждает последний
+func messageHeaders(msg *sarama.ConsumerMessage) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	return headers
+}
+
+func headerValue(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// consumeRetries обрабатывает топик повторов: каждое сообщение ждёт своего
+// срока, после чего проходит обычную обработку. Сообщения уровня приходят
+// в порядке сроков, поэтому ожидание первого не задерживает остальные.
+func (kc *KafkaConsumer) consumeRetries(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, tier RetryTier, handler MessageHandler) error {
+	ctx := sess.Context()
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				kc.logger.Info("message channel closed", "topic", claim.Topic(), "partition", claim.Partition())
+				return nil
+			}
+
+			if wait := time.Until(retryDueAt(msg, tier)); wait > 0 {
+				if !kc.sleep(ctx, wait) {
+					// сообщение не отмечено и будет прочитано снова
+					return nil
+				}
+			}
+
+			if err := kc.processMessage(ctx, handler, msg); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				kc.logger.Error("failed to process retried message, restarting session",
+					"topic", msg.Topic,
+					"partition", msg.Partition,
+					"offset", msg.Offset,
+					"error", err.Error())
+				return kc.failSession(msg, err)
+			}
+			sess.MarkMessage(msg, "")
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"l0/internal/config"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTopic(t *testing.T) {
+	assert.Equal(t, "orders.retry.10s", RetryTopic("orders", 10*time.Second))
+	assert.Equal(t, "orders.retry.1m", RetryTopic("orders", time.Minute))
+	assert.Equal(t, "orders.retry.2h", RetryTopic("orders", 2*time.Hour))
+	assert.Equal(t, "orders.retry.1500ms", RetryTopic("orders", 1500*time.Millisecond))
+}
+
+func TestKafkaConsumer_PersistFailureGoesThroughRetryTiers(t *testing.T) {
+	ctx := context.Background()
+	producer := mocks.NewSyncProducer(t, nil)
+	retries := NewRetryScheduler(producer, []string{"orders"}, []time.Duration{10 * time.Second, time.Minute})
+	retries.now = func() time.Time { return time.UnixMilli(1000) }
+
+	dlq := &recordingDLQ{}
+	db := &fakeDB{reject: map[string]bool{"a": true}}
+	kc := NewKafkaConsumer(config.Config{Kafka: config.KafkaConfig{MaxRetries: 5}}, slog.Default(), nil, orderHandlers(db), dlq, retries, nil)
+
+	var forwarded *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		forwarded = msg
+		return nil
+	})
+
+	msg := orderMessage(t, "a", 17)
+	msg.Topic, msg.Partition = "orders", 2
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), msg))
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "orders.retry.10s", forwarded.Topic)
+	assert.Empty(t, dlq.failures)
+
+	// сообщение из топика повторов уходит на следующий уровень
+	retried := consumed(forwarded)
+	assert.Equal(t, 1, RetryAttempt(retried))
+	assert.Equal(t, time.UnixMilli(11000), retryDueAt(retried, RetryTier{Delay: 10 * time.Second}))
+
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		forwarded = msg
+		return nil
+	})
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), retried))
+	assert.Equal(t, "orders.retry.1m", forwarded.Topic)
+
+	// повторы через топики учитываются в метрике, повторов на месте нет
+	retriesTotal := kc.Metrics().retries
+	assert.Equal(t, 1.0, testutil.ToFloat64(retriesTotal.WithLabelValues("orders", retryTopic)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(retriesTotal.WithLabelValues("orders.retry.10s", retryTopic)))
+	assert.Zero(t, testutil.ToFloat64(retriesTotal.WithLabelValues("orders", retryInline)))
+
+	// после последнего уровня — в DLQ
+	last := consumed(forwarded)
+	require.NoError(t, kc.processMessage(ctx, ordersHandler(kc), last))
+	require.Len(t, dlq.failures, 1)
+	assert.Equal(t, 3, dlq.failures[0].Attempts)
+	assert.Equal(t, "orders/2/17", headerValue(last, HeaderRetryOrigin))
+
+	require.NoError(t, producer.Close())
+}
+
+func TestKafkaConsumer_RetryPublishFailureIsNotMarked(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(errors.New("broker down"))
+	retries := NewRetryScheduler(producer, []string{"orders"}, []time.Duration{time.Second})
+	kc := NewKafkaConsumer(config.Config{}, slog.Default(), nil, orderHandlers(&fakeDB{reject: map[string]bool{"a": true}}), &recordingDLQ{}, retries, nil)
+
+	msg := orderMessage(t, "a", 1)
+	msg.Topic = "orders"
+	assert.Error(t, kc.processMessage(context.Background(), ordersHandler(kc), msg))
+	require.NoError(t, producer.Close())
+}
+
+// consumed превращает отправленное сообщение в прочитанное из топика
+func consumed(pm *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	value, _ := pm.Value.Encode()
+	msg := &sarama.ConsumerMessage{Topic: pm.Topic, Value: value}
+	for i := range pm.Headers {
+		msg.Headers = append(msg.Headers, &pm.Headers[i])
+	}
+	return msg
+}
@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"l0/internal/config"
+	"l0/internal/health"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Состояния консьюмера под надзором
+const (
+	SupervisorRunning    = "running"
+	SupervisorRestarting = "restarting"
+	SupervisorStopped    = "stopped"
+	SupervisorFailed     = "failed"
+)
+
+// recentErrorWindow сколько после ошибки консьюмер считается degraded
+const recentErrorWindow = time.Minute
+
+// Supervisor перезапускает консьюмер после фатальных ошибок с
+// экспоненциальной задержкой. При политике exit Run возвращает ошибку, и
+// процесс должен завершиться.
+type Supervisor struct {
+	consumer   *KafkaConsumer
+	logger     *slog.Logger
+	exitFatal  bool
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	state    string
+	restarts int
+	lastErr  error
+}
+
+func NewSupervisor(cfg config.KafkaConfig, logger *slog.Logger, consumer *KafkaConsumer) *Supervisor {
+	backoff := cfg.InitialBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return &Supervisor{
+		consumer:   consumer,
+		logger:     logger,
+		exitFatal:  cfg.FatalErrorPolicy == "exit",
+		backoff:    backoff,
+		maxBackoff: max(cfg.MaxBackoff, backoff),
+		state:      SupervisorStopped,
+	}
+}
+
+// Run запускает консьюмер и перезапускает его до отмены ctx. Ошибка
+// возвращается, только если политика требует завершить процесс.
+func (s *Supervisor) Run(ctx context.Context) error {
+	backoff := s.backoff
+	for {
+		s.setState(SupervisorRunning, nil)
+		started := time.Now()
+		err := s.consume(ctx)
+
+		if ctx.Err() != nil {
+			s.setState(SupervisorStopped, nil)
+			return nil
+		}
+		if err == nil {
+			s.logger.Info("consumer group closed, supervisor finished")
+			s.setState(SupervisorStopped, nil)
+			return nil
+		}
+
+		var msgErr *MessageError
+		if errors.As(err, &msgErr) || s.exitFatal {
+			s.logger.Error("consumer failed, stopping by policy", "error", err.Error())
+			s.setState(SupervisorFailed, err)
+			return err
+		}
+
+		// консьюмер проработал дольше предельной задержки — сбой новый
+		if time.Since(started) > s.maxBackoff {
+			backoff = s.backoff
+		}
+		s.mu.Lock()
+		s.restarts++
+		s.mu.Unlock()
+		s.setState(SupervisorRestarting, err)
+		s.logger.Warn("consumer failed, restarting", "backoff", backoff, "error", err.Error())
+		if !s.consumer.sleep(ctx, backoff) {
+			s.setState(SupervisorStopped, nil)
+			return nil
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// consume запускает консьюмер. Здесь перехватывается паника только в горутине
+// Consume, паники обработки сообщений перехватывают ConsumeClaim и обработчики
+// consumeParallel.
+func (s *Supervisor) consume(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer panic: %v", r)
+			s.consumer.errors.record(ErrorFatal, err)
+		}
+	}()
+	return s.consumer.Consume(ctx)
+}
+
+func (s *Supervisor) setState(state string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	if err != nil {
+		s.lastErr = err
+	}
+}
+
+// Check реализует health.Checker: состояние консьюмера, ошибки и отставание.
+// Остановленный по ошибке консьюмер — down, перезапуск или недавние ошибки —
+// degraded.
+func (s *Supervisor) Check(ctx context.Context) health.Result {
+	res := s.consumer.Check(ctx)
+
+	s.mu.Lock()
+	state, restarts, lastErr := s.state, s.restarts, s.lastErr
+	s.mu.Unlock()
+
+	res.Details["state"] = state
+	res.Details["restarts"] = restarts
+	res.Details["errors"] = s.consumer.errors.Stats()
+	if lastErr != nil {
+		res.Error = lastErr.Error()
+	}
+
+	switch {
+	case state == SupervisorFailed:
+		res.Status = health.StatusDown
+	case state == SupervisorRestarting:
+		res.Status = health.StatusDegraded
+	case time.Since(s.consumer.errors.lastAt(ErrorFatal, ErrorMessage)) < recentErrorWindow:
+		res.Status = health.StatusDegraded
+	}
+	return res
+}
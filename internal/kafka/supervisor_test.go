@@ -0,0 +1,156 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"l0/internal/config"
+	"l0/internal/domain"
+	"l0/internal/health"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyGroup не может провести первые fail сессий, затем сессии идут до
+// отмены или передают claim обработчику
+type flakyGroup struct {
+	sarama.ConsumerGroup
+	mu    sync.Mutex
+	fail  int
+	calls int
+	claim func() sarama.ConsumerGroupClaim
+}
+
+func (g *flakyGroup) Errors() <-chan error { return nil }
+
+func (g *flakyGroup) Consume(ctx context.Context, _ []string, handler sarama.ConsumerGroupHandler) error {
+	g.mu.Lock()
+	g.calls++
+	failing := g.calls <= g.fail
+	g.mu.Unlock()
+	if failing {
+		return errors.New("kafka: client has run out of available brokers")
+	}
+	if g.claim != nil {
+		_ = handler.ConsumeClaim(&fakeSession{ctx: ctx}, g.claim())
+		return nil
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestSupervisor_RestartsWithBackoff(t *testing.T) {
+	cfg := config.KafkaConfig{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	group := &flakyGroup{fail: 3}
+	kc := NewKafkaConsumer(config.Config{Kafka: cfg}, slog.Default(), group, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+	s := NewSupervisor(cfg, slog.Default(), kc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		res := s.Check(ctx)
+		return res.Details["state"] == SupervisorRunning && res.Details["restarts"] == 3
+	}, time.Second, time.Millisecond)
+
+	res := s.Check(ctx)
+	assert.Equal(t, health.StatusDegraded, res.Status, "recent fatal errors")
+	assert.Equal(t, int64(3), kc.Errors().Stats().Counts[ErrorFatal])
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestSupervisor_ExitPolicy(t *testing.T) {
+	t.Run("fatal", func(t *testing.T) {
+		cfg := config.KafkaConfig{FatalErrorPolicy: "exit"}
+		kc := NewKafkaConsumer(config.Config{Kafka: cfg}, slog.Default(), &flakyGroup{fail: 1}, orderHandlers(&fakeDB{}), &recordingDLQ{}, nil, nil)
+		s := NewSupervisor(cfg, slog.Default(), kc)
+
+		assert.Error(t, s.Run(context.Background()))
+		assert.Equal(t, health.StatusDown, s.Check(context.Background()).Status)
+	})
+
+	t.Run("message", func(t *testing.T) {
+		cfg := config.KafkaConfig{MessageErrorPolicy: "exit"}
+		group := &flakyGroup{claim: func() sarama.ConsumerGroupClaim {
+			claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+			claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Value: []byte("not json"), Offset: 7}
+			return claim
+		}}
+		dlq := &recordingDLQ{err: errors.New("broker unavailable")}
+		kc := NewKafkaConsumer(config.Config{Kafka: cfg}, slog.Default(), group, orderHandlers(&fakeDB{}), dlq, nil, nil)
+		s := NewSupervisor(cfg, slog.Default(), kc)
+
+		err := s.Run(context.Background())
+		var msgErr *MessageError
+		require.ErrorAs(t, err, &msgErr)
+		assert.Equal(t, int64(7), msgErr.Offset)
+		assert.Equal(t, int64(1), kc.Errors().Stats().Counts[ErrorMessage])
+	})
+}
+
+// panicDB паникует при сохранении заказа
+type panicDB struct{ fakeDB }
+
+func (db *panicDB) CreateOrder(context.Context, domain.Order) (int, error) {
+	panic("nil map write")
+}
+
+func TestSupervisor_HandlerPanic(t *testing.T) {
+	claim := func() sarama.ConsumerGroupClaim {
+		c := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+		c.messages <- orderMessage(t, "a", 0)
+		return c
+	}
+
+	for _, workers := range []int{0, 4} {
+		t.Run(fmt.Sprintf("exit/workers=%d", workers), func(t *testing.T) {
+			cfg := config.KafkaConfig{FatalErrorPolicy: "exit", Workers: workers}
+			kc := NewKafkaConsumer(config.Config{Kafka: cfg}, slog.Default(), &flakyGroup{claim: claim}, orderHandlers(&panicDB{}), &recordingDLQ{}, nil, nil)
+			s := NewSupervisor(cfg, slog.Default(), kc)
+
+			err := s.Run(context.Background())
+			require.ErrorIs(t, err, errConsumerPanic)
+			assert.Contains(t, err.Error(), "nil map write")
+			assert.Equal(t, int64(1), kc.Errors().Stats().Counts[ErrorFatal])
+			assert.Equal(t, health.StatusDown, s.Check(context.Background()).Status)
+		})
+	}
+
+	t.Run("restart", func(t *testing.T) {
+		cfg := config.KafkaConfig{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+		kc := NewKafkaConsumer(config.Config{Kafka: cfg}, slog.Default(), &flakyGroup{claim: claim}, orderHandlers(&panicDB{}), &recordingDLQ{}, nil, nil)
+		s := NewSupervisor(cfg, slog.Default(), kc)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- s.Run(ctx) }()
+		require.Eventually(t, func() bool {
+			restarts, _ := s.Check(ctx).Details["restarts"].(int)
+			return restarts >= 2
+		}, time.Second, time.Millisecond)
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestErrorLog_Bounded(t *testing.T) {
+	log := newErrorLog(3)
+	for i := range 5 {
+		log.record(ErrorGroup, fmt.Errorf("error %d", i))
+	}
+
+	stats := log.Stats()
+	assert.Equal(t, int64(5), stats.Counts[ErrorGroup])
+	require.Len(t, stats.Recent, 3)
+	assert.Equal(t, "error 4", stats.Recent[0].Error)
+	assert.Equal(t, "error 2", stats.Recent[2].Error)
+}
@@ -0,0 +1,23 @@
+create table quarantine (
+	id             bigserial not null primary key,
+	topic          varchar(256) not null,
+	partition      int not null,
+	"offset"       bigint not null,
+	message_key    bytea,
+	payload        bytea not null,
+	headers        jsonb not null default '{}',
+	stage          varchar(32) not null,
+	reason         text not null,
+	violations     jsonb not null default '[]',
+	attempts       int not null default 0,
+	status         varchar(16) not null default 'pending'
+		check (status in ('pending', 'replaying', 'replayed', 'discarded')),
+	discard_reason text,
+	order_id       bigint,
+	created_at     timestamptz not null default now(),
+	updated_at     timestamptz not null default now(),
+	resolved_at    timestamptz
+);
+
+create index quarantine_status_created_idx on quarantine (status, created_at);
+create index quarantine_stage_idx on quarantine (stage);
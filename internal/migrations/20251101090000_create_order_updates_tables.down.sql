@@ -0,0 +1,2 @@
+drop table if exists delivery_statuses;
+drop table if exists payment_confirmations;
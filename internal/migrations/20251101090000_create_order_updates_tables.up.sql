@@ -0,0 +1,24 @@
+create table payment_confirmations (
+	id           bigserial not null primary key,
+	order_uid    varchar(128) not null,
+	transaction  varchar(256) not null,
+	status       varchar(32) not null,
+	amount       int not null,
+	currency     varchar(3) not null,
+	confirmed_at timestamptz not null,
+	received_at  timestamptz not null default now(),
+	unique (order_uid, transaction, status)
+);
+
+create table delivery_statuses (
+	id           bigserial not null primary key,
+	order_uid    varchar(128) not null,
+	track_number varchar(128) not null,
+	status       varchar(32) not null,
+	location     varchar(256) not null default '',
+	updated_at   timestamptz not null,
+	received_at  timestamptz not null default now(),
+	unique (order_uid, status, updated_at)
+);
+
+create index delivery_statuses_order_uid_idx on delivery_statuses (order_uid, updated_at);
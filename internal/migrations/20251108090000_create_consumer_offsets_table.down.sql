@@ -0,0 +1 @@
+drop table if exists consumer_offsets;
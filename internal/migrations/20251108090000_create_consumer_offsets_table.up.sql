@@ -0,0 +1,8 @@
+create table consumer_offsets (
+	consumer_group varchar(256) not null,
+	topic          varchar(256) not null,
+	partition      int not null,
+	next_offset    bigint not null,
+	updated_at     timestamptz not null default now(),
+	primary key (consumer_group, topic, partition)
+);
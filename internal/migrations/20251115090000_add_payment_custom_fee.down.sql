@@ -0,0 +1 @@
+alter table payment drop column if exists CustomFee;
@@ -0,0 +1 @@
+alter table payment add column CustomFee int not null default 0;
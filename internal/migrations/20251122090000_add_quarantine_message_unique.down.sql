@@ -0,0 +1 @@
+drop index if exists quarantine_message_idx;
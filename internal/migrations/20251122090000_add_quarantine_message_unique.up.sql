@@ -0,0 +1,6 @@
+-- повторная доставка сообщения не должна создавать новую запись карантина:
+-- из дублей остаётся первая
+delete from quarantine q using quarantine d
+	where q.topic = d.topic and q.partition = d.partition and q."offset" = d."offset" and q.id > d.id;
+
+create unique index quarantine_message_idx on quarantine (topic, partition, "offset");
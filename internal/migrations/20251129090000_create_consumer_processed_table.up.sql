@@ -0,0 +1,10 @@
+-- обработанные сообщения после позиции партиции из consumer_offsets:
+-- параллельные обработчики завершают сообщения не по порядку смещений
+create table consumer_processed (
+	consumer_group varchar(256) not null,
+	topic          varchar(256) not null,
+	partition      int not null,
+	"offset"       bigint not null,
+	created_at     timestamptz not null default now(),
+	primary key (consumer_group, topic, partition, "offset")
+);
@@ -0,0 +1,119 @@
+package producer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"l0/internal/domain"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	entries    = []string{"WBIL", "WBRU", "WBKZ"}
+	locales    = []string{"en", "ru"}
+	currencies = []string{"USD", "RUB", "EUR"}
+	providers  = []string{"wbpay", "sbp", "card"}
+	banks      = []string{"alpha", "sber", "tinkoff", "vtb"}
+	services   = []string{"meest", "cdek", "boxberry", "wb"}
+	names      = []string{"Test Testov", "Ivan Petrov", "Anna Smirnova", "Oleg Ivanov", "Maria Kuznetsova"}
+	cities     = []string{"Moscow", "Kazan", "Novosibirsk", "Kiryat Mozkin", "Almaty"}
+	regions    = []string{"Moscow", "Tatarstan", "Novosibirsk", "Kraiot", "Almaty"}
+	streets    = []string{"Ploshad Mira", "Lenina", "Tverskaya", "Sadovaya", "Pushkina"}
+	products   = []string{"Mascaras", "T-shirt", "Sneakers", "Backpack", "Lipstick", "Headphones"}
+	brands     = []string{"Vivienne Sabo", "Nike", "Adidas", "Xiaomi", "Maybelline"}
+)
+
+// Generator создаёт случайные заказы, проходящие проверку domain:
+// уникальный order_uid и согласованные суммы позиций и оплаты
+type Generator struct {
+	rnd *rand.Rand
+	now func() time.Time
+}
+
+// NewGenerator генератор с заданным seed, одинаковый seed даёт одинаковые заказы
+func NewGenerator(seed uint64) *Generator {
+	return &Generator{
+		rnd: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		now: time.Now,
+	}
+}
+
+// Order создаёт следующий заказ
+func (g *Generator) Order() domain.Order {
+	uid := g.hex(16) + "gen"
+	track := "WBIL" + g.upper(10)
+
+	items := make([]domain.Items, 1+g.rnd.IntN(4))
+	goods := 0
+	for i := range items {
+		price := 100 + g.rnd.IntN(9900)
+		sale := g.rnd.IntN(8) * 10
+		total := price * (100 - sale) / 100
+		goods += total
+		items[i] = domain.Items{
+			ChrtID:     1_000_000 + g.rnd.IntN(9_000_000),
+			Price:      price,
+			Rid:        g.hex(16) + "gen",
+			Name:       pick(g.rnd, products),
+			Sale:       sale,
+			Size:       fmt.Sprint(g.rnd.IntN(6)),
+			TotalPrice: total,
+			NmID:       1_000_000 + g.rnd.IntN(9_000_000),
+			Brand:      pick(g.rnd, brands),
+		}
+	}
+	deliveryCost := 100 * (1 + g.rnd.IntN(20))
+
+	i := g.rnd.IntN(len(cities))
+	return domain.Order{
+		OrderUID:    uid,
+		Entry:       pick(g.rnd, entries),
+		TrackNumber: track,
+		Payment: domain.Payment{
+			Transaction:  uid,
+			Currency:     pick(g.rnd, currencies),
+			Provider:     pick(g.rnd, providers),
+			Amount:       goods + deliveryCost,
+			PaymentDt:    int(g.now().Add(-time.Duration(g.rnd.IntN(30*24)) * time.Hour).Unix()),
+			Bank:         pick(g.rnd, banks),
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   goods,
+		},
+		Items:           items,
+		Locale:          pick(g.rnd, locales),
+		CustomerID:      "customer" + fmt.Sprint(g.rnd.IntN(1000)),
+		DeliveryService: pick(g.rnd, services),
+		Shardkey:        fmt.Sprint(g.rnd.IntN(10)),
+		SmID:            1 + g.rnd.IntN(100),
+		Delivery: domain.Delivery{
+			Name:    pick(g.rnd, names),
+			Phone:   fmt.Sprintf("+7%010d", g.rnd.Int64N(10_000_000_000)),
+			Zip:     fmt.Sprintf("%07d", g.rnd.IntN(10_000_000)),
+			City:    cities[i],
+			Address: fmt.Sprintf("%s %d", pick(g.rnd, streets), 1+g.rnd.IntN(200)),
+			Region:  regions[i],
+			Email:   fmt.Sprintf("user%d@example.com", g.rnd.IntN(100_000)),
+		},
+	}
+}
+
+// hex случайная строка из n шестнадцатеричных символов
+func (g *Generator) hex(n int) string {
+	b := make([]byte, (n+1)/2)
+	for i := range b {
+		b[i] = byte(g.rnd.UintN(256))
+	}
+	return hex.EncodeToString(b)[:n]
+}
+
+func (g *Generator) upper(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('A' + g.rnd.IntN(26))
+	}
+	return string(b)
+}
+
+func pick(rnd *rand.Rand, values []string) string {
+	return values[rnd.IntN(len(values))]
+}
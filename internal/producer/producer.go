@@ -0,0 +1,162 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Стратегии выбора ключа сообщения
+const (
+	// KeyOrderUID ключ — order_uid, сообщения заказа попадают в одну партицию
+	KeyOrderUID = "uid"
+	// KeyRandom случайный ключ на каждое сообщение
+	KeyRandom = "random"
+	// KeyNone без ключа, партиции выбираются продюсером
+	KeyNone = "none"
+)
+
+// Options параметры отправки
+type Options struct {
+	Topic string
+	// Rate сообщений в секунду, 0 — без ограничения
+	Rate float64
+	Keys string
+	// Headers заголовки каждого сообщения, например версия схемы
+	Headers map[string]string
+}
+
+// Failed сообщение, которое не удалось доставить
+type Failed struct {
+	Index int
+	Key   string
+	Err   error
+}
+
+// Report итог отправки
+type Report struct {
+	Sent       int
+	Delivered  int
+	Partitions map[int32]int
+	Failed     []Failed
+	Duration   time.Duration
+}
+
+// ValidateKeys проверяет стратегию выбора ключа
+func ValidateKeys(keys string) error {
+	switch keys {
+	case "", KeyOrderUID, KeyRandom, KeyNone:
+		return nil
+	default:
+		return fmt.Errorf("unknown key strategy %q", keys)
+	}
+}
+
+// Publish отправляет сообщения с заданной частотой и дожидается
+// подтверждения каждого. Продюсер должен возвращать Successes и Errors;
+// Publish закрывает его после отправки, в том числе при ошибке в opts.
+// Ошибка означает, что отправка прервана; недоставленные сообщения
+// перечислены в Report.Failed.
+func Publish(ctx context.Context, producer sarama.AsyncProducer, msgs []json.RawMessage, opts Options) (Report, error) {
+	if err := ValidateKeys(opts.Keys); err != nil {
+		_ = producer.Close()
+		return Report{}, err
+	}
+
+	report := Report{Partitions: make(map[int32]int)}
+	start := time.Now()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for m := range producer.Successes() {
+			mu.Lock()
+			report.Delivered++
+			report.Partitions[m.Partition]++
+			mu.Unlock()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for perr := range producer.Errors() {
+			f := Failed{Err: perr.Err}
+			if perr.Msg != nil {
+				f.Index, _ = perr.Msg.Metadata.(int)
+				if perr.Msg.Key != nil {
+					key, _ := perr.Msg.Key.Encode()
+					f.Key = string(key)
+				}
+			}
+			mu.Lock()
+			report.Failed = append(report.Failed, f)
+			mu.Unlock()
+		}
+	}()
+
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var err error
+send:
+	for i, body := range msgs {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				err = ctx.Err()
+				break send
+			}
+		}
+
+		msg := &sarama.ProducerMessage{
+			Topic:    opts.Topic,
+			Value:    sarama.ByteEncoder(body),
+			Metadata: i,
+		}
+		for k, v := range opts.Headers {
+			msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+		}
+		if key := messageKey(opts.Keys, body, i); key != "" {
+			msg.Key = sarama.StringEncoder(key)
+		}
+
+		select {
+		case producer.Input() <- msg:
+			report.Sent++
+		case <-ctx.Done():
+			err = ctx.Err()
+			break send
+		}
+	}
+
+	// после AsyncClose каналы результатов закрываются, когда все
+	// отправленные сообщения подтверждены или завершились ошибкой
+	producer.AsyncClose()
+	wg.Wait()
+	report.Duration = time.Since(start)
+	sort.Slice(report.Failed, func(i, j int) bool { return report.Failed[i].Index < report.Failed[j].Index })
+	return report, err
+}
+
+func messageKey(strategy string, body json.RawMessage, i int) string {
+	switch strategy {
+	case KeyNone:
+		return ""
+	case KeyRandom:
+		return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.Itoa(i)
+	default:
+		return orderUID(body)
+	}
+}
@@ -0,0 +1,81 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"l0/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_OrdersAreValid(t *testing.T) {
+	gen := NewGenerator(42)
+	v := validator.New()
+	seen := make(map[string]bool)
+
+	for range 200 {
+		o := gen.Order()
+		require.NoError(t, v.Struct(o))
+		require.NoError(t, domain.ValidateOrder(o))
+		assert.False(t, seen[o.OrderUID], "order_uid must be unique")
+		seen[o.OrderUID] = true
+	}
+}
+
+func TestReadMessages(t *testing.T) {
+	for name, input := range map[string]string{
+		"array":  `[{"order_uid":"a"}, {"order_uid":"b"}]`,
+		"ndjson": "{\"order_uid\":\"a\"}\n{\"order_uid\":\"b\"}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			msgs, err := ReadMessages(strings.NewReader(input))
+			require.NoError(t, err)
+			require.Len(t, msgs, 2)
+			assert.Equal(t, "a", orderUID(msgs[0]))
+			assert.Equal(t, "b", orderUID(msgs[1]))
+		})
+	}
+
+	_, err := ReadMessages(strings.NewReader("  \n"))
+	assert.Error(t, err)
+}
+
+func TestPublish_ReportsDeliveryResults(t *testing.T) {
+	sc := mocks.NewTestConfig()
+	sc.Producer.Return.Successes = true
+	p := mocks.NewAsyncProducer(t, sc)
+	p.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, "a", string(key))
+		return nil
+	})
+	p.ExpectInputAndFail(errors.New("broker down"))
+
+	msgs := []json.RawMessage{json.RawMessage(`{"order_uid":"a"}`), json.RawMessage(`{"order_uid":"b"}`)}
+	report, err := Publish(context.Background(), p, msgs, Options{Topic: "orders", Keys: KeyOrderUID})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Sent)
+	assert.Equal(t, 1, report.Delivered)
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, 1, report.Failed[0].Index)
+	assert.Equal(t, "b", report.Failed[0].Key)
+}
+
+func TestPublish_UnknownKeysClosesProducer(t *testing.T) {
+	sc := mocks.NewTestConfig()
+	sc.Producer.Return.Successes = true
+	p := mocks.NewAsyncProducer(t, sc)
+
+	_, err := Publish(context.Background(), p, []json.RawMessage{json.RawMessage(`{}`)}, Options{Topic: "orders", Keys: "hash"})
+	require.ErrorContains(t, err, `unknown key strategy "hash"`)
+	_, open := <-p.Successes()
+	assert.False(t, open, "producer must be closed")
+}
@@ -0,0 +1,69 @@
+package producer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReadMessages читает заказы из JSON (объект или массив) или NDJSON.
+// Тела сообщений не проверяются и отправляются как есть, чтобы можно было
+// проверять обработку некорректных заказов.
+func ReadMessages(r io.Reader) ([]json.RawMessage, error) {
+	br := bufio.NewReader(r)
+	first, err := firstByte(br)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var msgs []json.RawMessage
+		if err := dec.Decode(&msgs); err != nil {
+			return nil, fmt.Errorf("decode array: %w", err)
+		}
+		return msgs, nil
+	}
+
+	// один объект или NDJSON: значения идут друг за другом
+	var msgs []json.RawMessage
+	for {
+		var msg json.RawMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return msgs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode message %d: %w", len(msgs)+1, err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// firstByte первый значимый символ без его извлечения из потока
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return 0, fmt.Errorf("input is empty")
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			return b[0], nil
+		}
+		_, _ = br.ReadByte()
+	}
+}
+
+// orderUID ключ заказа из тела сообщения, пусто — поле не найдено
+func orderUID(msg json.RawMessage) string {
+	var v struct {
+		OrderUID string `json:"order_uid"`
+	}
+	_ = json.Unmarshal(msg, &v)
+	return v.OrderUID
+}
@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"l0/internal/domain"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Engine действующий набор правил из файла. Заказы проверяются без
+// блокировок, при изменении файла набор заменяется целиком.
+type Engine struct {
+	path   string
+	logger *slog.Logger
+	set    atomic.Pointer[RuleSet]
+
+	mu       sync.Mutex
+	sum      [sha256.Size]byte
+	badSum   [sha256.Size]byte
+	loadedAt time.Time
+	lastErr  error
+}
+
+// Status действующие правила и итог последней загрузки файла
+type Status struct {
+	File     string    `json:"file,omitempty"`
+	LoadedAt time.Time `json:"loaded_at,omitempty"`
+	// Error ошибка последней перезагрузки; при ней действуют прежние правила
+	Error string `json:"error,omitempty"`
+	Rules []Rule `json:"rules"`
+}
+
+// Report итог пробной проверки заказа
+type Report struct {
+	Accepted   bool               `json:"accepted"`
+	Results    []Result           `json:"results"`
+	Violations []domain.Violation `json:"violations,omitempty"`
+}
+
+// NewEngine загружает правила из path. Пустой path — правил нет, некорректный
+// файл при старте — ошибка.
+func NewEngine(path string, logger *slog.Logger) (*Engine, error) {
+	e := &Engine{path: path, logger: logger}
+	e.set.Store(&RuleSet{})
+	if path == "" {
+		return e, nil
+	}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload перечитывает файл и заменяет набор, если содержимое изменилось.
+// При ошибке остаются прежние правила.
+func (e *Engine) Reload() (bool, error) {
+	if e.path == "" {
+		return false, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		e.lastErr = fmt.Errorf("read rules: %w", err)
+		return false, e.lastErr
+	}
+	sum := sha256.Sum256(data)
+	if sum == e.sum && e.lastErr == nil {
+		return false, nil
+	}
+	// та же некорректная версия уже отклонена, ошибка видна в Status
+	if sum == e.badSum && e.lastErr != nil {
+		return false, nil
+	}
+	set, err := Parse(data)
+	if err != nil {
+		e.lastErr = err
+		e.badSum = sum
+		return false, err
+	}
+	changed := sum != e.sum
+	e.set.Store(set)
+	e.sum = sum
+	e.loadedAt = time.Now()
+	e.lastErr = nil
+	return changed, nil
+}
+
+// Watch перечитывает файл раз в interval до отмены ctx
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	if e.path == "" || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := e.Reload()
+			if err != nil {
+				e.logger.Error("rules reload failed, keeping previous rules", "file", e.path, "error", err.Error())
+				continue
+			}
+			if changed {
+				e.logger.Info("rules reloaded", "file", e.path, "rules", e.set.Load().Len())
+			}
+		}
+	}
+}
+
+// Check проверяет заказ действующими правилами
+func (e *Engine) Check(o domain.Order) error {
+	return e.set.Load().Check(o)
+}
+
+// Status возвращает действующие правила
+func (e *Engine) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := Status{File: e.path, LoadedAt: e.loadedAt, Rules: e.set.Load().Rules}
+	if e.lastErr != nil {
+		st.Error = e.lastErr.Error()
+	}
+	return st
+}
+
+// DryRun проверяет заказ правилами из src, а если src пуст — действующими.
+// Действующий набор не меняется.
+func (e *Engine) DryRun(o domain.Order, src []byte) (Report, error) {
+	set := e.set.Load()
+	if len(src) > 0 {
+		var err error
+		if set, err = Parse(src); err != nil {
+			return Report{}, err
+		}
+	}
+	report := Report{Accepted: true, Results: set.Evaluate(o)}
+	for _, res := range report.Results {
+		if res.Violation != nil {
+			report.Accepted = false
+			report.Violations = append(report.Violations, *res.Violation)
+		}
+	}
+	return report, nil
+}
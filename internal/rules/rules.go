@@ -0,0 +1,196 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"l0/internal/domain"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule правило проверки заказа. Выражения пишутся на expr
+// (https://expr-lang.org) над полями domain.Order с именами как в Go:
+// Payment.Currency, len(Items), Delivery.Region.
+type Rule struct {
+	// ID попадает в причину отказа, должен быть уникальным
+	ID          string `yaml:"id" json:"id"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Entries маркетплейсы, к которым применяется правило; пустой — ко всем
+	Entries []string `yaml:"entries,omitempty" json:"entries,omitempty"`
+	// When условие применения правила, необязательное
+	When string `yaml:"when,omitempty" json:"when,omitempty"`
+	// Assert условие, которому должен удовлетворять заказ
+	Assert string `yaml:"assert" json:"assert"`
+	// Field проверяемое поле, его значение попадает в нарушение как actual
+	Field   string `yaml:"field,omitempty" json:"field,omitempty"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	when   *vm.Program
+	assert *vm.Program
+	field  *vm.Program
+}
+
+// RuleSet скомпилированный набор правил
+type RuleSet struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Result итог проверки заказа одним правилом
+type Result struct {
+	Rule string `json:"rule"`
+	// Applied правило подходит по Entry и условию when
+	Applied   bool              `json:"applied"`
+	Passed    bool              `json:"passed"`
+	Violation *domain.Violation `json:"violation,omitempty"`
+}
+
+// Parse разбирает и компилирует правила из YAML. Ошибка содержит
+// идентификаторы всех некорректных правил.
+func Parse(data []byte) (*RuleSet, error) {
+	set := &RuleSet{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// опечатка в имени ключа не должна молча отключать условие
+	dec.KnownFields(true)
+	if err := dec.Decode(set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+
+	var errs []error
+	seen := make(map[string]struct{}, len(set.Rules))
+	for i := range set.Rules {
+		r := &set.Rules[i]
+		if r.ID == "" {
+			errs = append(errs, fmt.Errorf("rule #%d: id is required", i+1))
+			continue
+		}
+		if _, ok := seen[r.ID]; ok {
+			errs = append(errs, fmt.Errorf("rule %s: duplicate id", r.ID))
+			continue
+		}
+		seen[r.ID] = struct{}{}
+		if err := r.compile(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", r.ID, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Load читает и компилирует файл правил
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	return Parse(data)
+}
+
+func (r *Rule) compile() error {
+	if strings.TrimSpace(r.Assert) == "" {
+		return errors.New("assert is required")
+	}
+	var err error
+	if r.assert, err = expr.Compile(r.Assert, expr.Env(domain.Order{}), expr.AsBool()); err != nil {
+		return fmt.Errorf("assert: %w", err)
+	}
+	if r.When != "" {
+		if r.when, err = expr.Compile(r.When, expr.Env(domain.Order{}), expr.AsBool()); err != nil {
+			return fmt.Errorf("when: %w", err)
+		}
+	}
+	if r.Field != "" {
+		if r.field, err = expr.Compile(r.Field, expr.Env(domain.Order{})); err != nil {
+			return fmt.Errorf("field: %w", err)
+		}
+	}
+	return nil
+}
+
+// Len количество правил, nil-набор пуст
+func (s *RuleSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.Rules)
+}
+
+// Evaluate проверяет заказ каждым правилом набора
+func (s *RuleSet) Evaluate(o domain.Order) []Result {
+	if s == nil {
+		return nil
+	}
+	results := make([]Result, 0, len(s.Rules))
+	for i := range s.Rules {
+		results = append(results, s.Rules[i].evaluate(o))
+	}
+	return results
+}
+
+// Check возвращает *domain.ValidationError со всеми нарушенными правилами
+func (s *RuleSet) Check(o domain.Order) error {
+	var vs []domain.Violation
+	for _, res := range s.Evaluate(o) {
+		if res.Violation != nil {
+			vs = append(vs, *res.Violation)
+		}
+	}
+	if len(vs) > 0 {
+		return &domain.ValidationError{Violations: vs}
+	}
+	return nil
+}
+
+func (r *Rule) evaluate(o domain.Order) Result {
+	res := Result{Rule: r.ID, Passed: true}
+	if len(r.Entries) > 0 && !slices.Contains(r.Entries, o.Entry) {
+		return res
+	}
+	if r.when != nil {
+		ok, err := expr.Run(r.when, o)
+		if err != nil {
+			return r.failed(res, o, fmt.Sprintf("rule %s: when: %v", r.ID, err))
+		}
+		if !ok.(bool) {
+			return res
+		}
+	}
+	res.Applied = true
+
+	ok, err := expr.Run(r.assert, o)
+	if err != nil {
+		// ошибка вычисления, например выход за границы Items, считается
+		// нарушением: заказ уходит в карантин и может быть повторён после
+		// исправления правила
+		return r.failed(res, o, fmt.Sprintf("rule %s: assert: %v", r.ID, err))
+	}
+	if ok.(bool) {
+		return res
+	}
+	msg := r.Message
+	if msg == "" {
+		msg = "must satisfy " + r.Assert
+	}
+	return r.failed(res, o, msg)
+}
+
+func (r *Rule) failed(res Result, o domain.Order, msg string) Result {
+	res.Applied = true
+	res.Passed = false
+	v := &domain.Violation{Field: r.Field, Rule: r.ID, Message: msg}
+	if r.field != nil {
+		if actual, err := expr.Run(r.field, o); err == nil {
+			v.Actual = actual
+		}
+	}
+	res.Violation = v
+	return res
+}
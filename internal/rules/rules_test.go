@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"l0/internal/domain"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wbilOrder() domain.Order {
+	return domain.Order{
+		OrderUID:        "b563feb7b2b84b6test",
+		Entry:           "WBIL",
+		DeliveryService: "meest",
+		Payment:         domain.Payment{Currency: "USD"},
+		Delivery:        domain.Delivery{Region: "Kraiot"},
+		Items:           []domain.Items{{Price: 453}},
+	}
+}
+
+func TestParse_ExampleFile(t *testing.T) {
+	set, err := Load("../../rules/orders.example.yaml")
+	require.NoError(t, err)
+	require.Equal(t, 4, set.Len())
+
+	o := wbilOrder()
+	require.NoError(t, set.Check(o))
+
+	o.Payment.Currency = "RUB"
+	var verr *domain.ValidationError
+	require.True(t, errors.As(set.Check(o), &verr))
+	assert.Equal(t, []domain.Violation{{
+		Field: "Payment.Currency", Rule: "wbil-currency", Message: "currency must be USD or EUR", Actual: "RUB",
+	}}, verr.Violations)
+	assert.Contains(t, verr.Error(), "[wbil-currency]")
+
+	// правило другого Entry не применяется
+	o.Entry = "WBRU"
+	results := set.Evaluate(o)
+	require.Len(t, results, 4)
+	for _, res := range results {
+		assert.True(t, res.Passed, res.Rule)
+	}
+	assert.False(t, results[0].Applied)
+	assert.True(t, results[1].Applied)
+	assert.False(t, results[2].Applied, "when does not match")
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "no id", src: "rules:\n  - assert: len(Items) > 0\n", wantErr: "rule #1: id is required"},
+		{name: "duplicate", src: "rules:\n  - {id: a, assert: 'true'}\n  - {id: a, assert: 'true'}\n", wantErr: "rule a: duplicate id"},
+		{name: "no assert", src: "rules:\n  - id: a\n", wantErr: "rule a: assert is required"},
+		{name: "unknown field", src: "rules:\n  - {id: a, assert: Payment.Curency == 'USD'}\n", wantErr: "rule a: assert"},
+		{name: "not bool", src: "rules:\n  - {id: a, assert: len(Items)}\n", wantErr: "rule a: assert"},
+		{name: "typo in key", src: "rules:\n  - {id: a, asert: 'true'}\n", wantErr: "field asert not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRule_EvaluationErrorIsViolation(t *testing.T) {
+	set, err := Parse([]byte("rules:\n  - {id: second-item, assert: 'Items[1].Price > 0'}\n"))
+	require.NoError(t, err)
+
+	var verr *domain.ValidationError
+	require.True(t, errors.As(set.Check(wbilOrder()), &verr))
+	assert.Equal(t, "second-item", verr.Violations[0].Rule)
+	assert.Contains(t, verr.Violations[0].Message, "rule second-item: assert")
+}
+
+func TestEngine_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(src string) {
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	write("rules:\n  - {id: usd-only, assert: Payment.Currency == 'USD'}\n")
+
+	engine, err := NewEngine(path, slog.Default())
+	require.NoError(t, err)
+	o := wbilOrder()
+	require.NoError(t, engine.Check(o))
+
+	changed, err := engine.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "file is unchanged")
+
+	// некорректный файл не заменяет действующие правила
+	write("rules:\n  - {id: broken, assert: Payment.Currency ==}\n")
+	_, err = engine.Reload()
+	require.Error(t, err)
+	require.NoError(t, engine.Check(o))
+	status := engine.Status()
+	assert.NotEmpty(t, status.Error)
+	assert.Equal(t, "usd-only", status.Rules[0].ID)
+
+	write("rules:\n  - {id: eur-only, assert: Payment.Currency == 'EUR'}\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Watch(ctx, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return engine.Check(o) != nil }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, engine.Status().Error)
+
+	// пробная проверка не меняет действующие правила
+	report, err := engine.DryRun(o, []byte("rules:\n  - {id: any, assert: 'true'}\n"))
+	require.NoError(t, err)
+	assert.True(t, report.Accepted)
+	assert.Error(t, engine.Check(o))
+
+	_, err = NewEngine(filepath.Join(t.TempDir(), "missing.yaml"), slog.Default())
+	assert.Error(t, err)
+}
@@ -0,0 +1,515 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Avro разобранная схема Avro. Поддерживает бинарное кодирование всех типов
+// спецификации, кроме logical types, которые читаются как базовые типы.
+type Avro struct {
+	root *avroType
+}
+
+type avroType struct {
+	kind     string
+	name     string
+	fields   []avroField
+	items    *avroType // array
+	values   *avroType // map
+	branches []*avroType
+	symbols  []string
+	size     int
+}
+
+type avroField struct {
+	name   string
+	typ    *avroType
+	def    any
+	hasDef bool
+}
+
+var avroPrimitives = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true,
+	"float": true, "double": true, "bytes": true, "string": true,
+}
+
+// ParseAvro разбирает схему в формате .avsc
+func ParseAvro(src string) (*Avro, error) {
+	var node any
+	if err := json.Unmarshal([]byte(src), &node); err != nil {
+		return nil, fmt.Errorf("parse avro schema: %w", err)
+	}
+	p := avroParser{names: make(map[string]*avroType)}
+	root, err := p.parse(node, "")
+	if err != nil {
+		return nil, fmt.Errorf("parse avro schema: %w", err)
+	}
+	return &Avro{root: root}, nil
+}
+
+type avroParser struct {
+	names map[string]*avroType
+}
+
+func (p *avroParser) parse(node any, namespace string) (*avroType, error) {
+	switch n := node.(type) {
+	case string:
+		if avroPrimitives[n] {
+			return &avroType{kind: n}, nil
+		}
+		if t, ok := p.names[p.fullName(n, namespace)]; ok {
+			return t, nil
+		}
+		if t, ok := p.names[n]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", n)
+
+	case []any:
+		t := &avroType{kind: "union"}
+		for _, b := range n {
+			bt, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			t.branches = append(t.branches, bt)
+		}
+		return t, nil
+
+	case map[string]any:
+		kind, _ := n["type"].(string)
+		if kind == "" {
+			// {"type": {...}} или {"type": [...]}
+			return p.parse(n["type"], namespace)
+		}
+		if ns, ok := n["namespace"].(string); ok {
+			namespace = ns
+		}
+		name, _ := n["name"].(string)
+
+		switch kind {
+		case "record", "error":
+			t := &avroType{kind: "record", name: p.fullName(name, namespace)}
+			p.names[t.name] = t
+			if i := strings.LastIndexByte(t.name, '.'); i >= 0 {
+				namespace = t.name[:i]
+			}
+			fields, _ := n["fields"].([]any)
+			for _, f := range fields {
+				fm, ok := f.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("record %s: invalid field", t.name)
+				}
+				fname, _ := fm["name"].(string)
+				ft, err := p.parse(fm["type"], namespace)
+				if err != nil {
+					return nil, fmt.Errorf("record %s field %s: %w", t.name, fname, err)
+				}
+				def, hasDef := fm["default"]
+				t.fields = append(t.fields, avroField{name: fname, typ: ft, def: def, hasDef: hasDef})
+			}
+			return t, nil
+
+		case "enum":
+			t := &avroType{kind: "enum", name: p.fullName(name, namespace)}
+			symbols, ok := n["symbols"].([]any)
+			if !ok {
+				return nil, fmt.Errorf("enum %s: symbols must be an array", t.name)
+			}
+			for _, s := range symbols {
+				sym, ok := s.(string)
+				if !ok {
+					return nil, fmt.Errorf("enum %s: invalid symbol", t.name)
+				}
+				t.symbols = append(t.symbols, sym)
+			}
+			p.names[t.name] = t
+			return t, nil
+
+		case "fixed":
+			size, _ := n["size"].(float64)
+			t := &avroType{kind: "fixed", name: p.fullName(name, namespace), size: int(size)}
+			p.names[t.name] = t
+			return t, nil
+
+		case "array":
+			items, err := p.parse(n["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{kind: "array", items: items}, nil
+
+		case "map":
+			values, err := p.parse(n["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{kind: "map", values: values}, nil
+
+		default:
+			// примитив с logicalType
+			return p.parse(kind, namespace)
+		}
+	}
+	return nil, fmt.Errorf("invalid schema node %v", node)
+}
+
+func (p *avroParser) fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// Decode читает значение из бинарного представления. Записи возвращаются
+// как map[string]any, числа int и long как int64.
+func (a *Avro) Decode(b []byte) (any, error) {
+	r := avroReader{b: b}
+	v := r.read(a.root)
+	if r.err != nil {
+		return nil, fmt.Errorf("decode avro: %w", r.err)
+	}
+	if len(r.b) > 0 {
+		return nil, fmt.Errorf("decode avro: %d trailing bytes", len(r.b))
+	}
+	return v, nil
+}
+
+type avroReader struct {
+	b   []byte
+	err error
+}
+
+var errAvroShort = errors.New("unexpected end of data")
+
+func (r *avroReader) long() int64 {
+	if r.err != nil {
+		return 0
+	}
+	u, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = errAvroShort
+		return 0
+	}
+	r.b = r.b[n:]
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *avroReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = errAvroShort
+		return nil
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *avroReader) read(t *avroType) any {
+	if r.err != nil {
+		return nil
+	}
+	switch t.kind {
+	case "null":
+		return nil
+	case "boolean":
+		b := r.next(1)
+		return b != nil && b[0] != 0
+	case "int", "long":
+		return r.long()
+	case "float":
+		b := r.next(4)
+		if b == nil {
+			return nil
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case "double":
+		b := r.next(8)
+		if b == nil {
+			return nil
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case "bytes":
+		return bytes.Clone(r.next(int(r.long())))
+	case "string":
+		return string(r.next(int(r.long())))
+	case "fixed":
+		return bytes.Clone(r.next(t.size))
+	case "enum":
+		i := r.long()
+		if r.err == nil && (i < 0 || int(i) >= len(t.symbols)) {
+			r.err = fmt.Errorf("enum %s: index %d out of range", t.name, i)
+			return nil
+		}
+		if r.err != nil {
+			return nil
+		}
+		return t.symbols[i]
+	case "union":
+		i := r.long()
+		if r.err == nil && (i < 0 || int(i) >= len(t.branches)) {
+			r.err = fmt.Errorf("union branch %d out of range", i)
+		}
+		if r.err != nil {
+			return nil
+		}
+		return r.read(t.branches[i])
+	case "record":
+		out := make(map[string]any, len(t.fields))
+		for _, f := range t.fields {
+			out[f.name] = r.read(f.typ)
+		}
+		return out
+	case "array":
+		var out []any
+		r.blocks(t.items.zeroSize(nil), func() { out = append(out, r.read(t.items)) })
+		return out
+	case "map":
+		out := make(map[string]any)
+		r.blocks(false, func() {
+			k := string(r.next(int(r.long())))
+			out[k] = r.read(t.values)
+		})
+		return out
+	}
+	r.err = fmt.Errorf("unsupported type %s", t.kind)
+	return nil
+}
+
+// avroMaxZeroSizeItems предел числа элементов нулевого размера (null) в
+// массиве: их число не ограничено длиной данных
+const avroMaxZeroSizeItems = 1 << 16
+
+// blocks читает блоки массива или словаря до блока нулевой длины. Элемент
+// ненулевого размера занимает хотя бы байт, поэтому блок не может быть
+// длиннее остатка данных.
+func (r *avroReader) blocks(zeroSize bool, item func()) {
+	total := int64(0)
+	for r.err == nil {
+		count := r.long()
+		if r.err != nil || count == 0 {
+			return
+		}
+		if count < 0 {
+			count = -count
+			r.long() // размер блока в байтах
+		}
+		switch {
+		case count < 0 || !zeroSize && count > int64(len(r.b)):
+			r.err = fmt.Errorf("block of %d items exceeds %d remaining bytes", count, len(r.b))
+			return
+		case zeroSize && count > avroMaxZeroSizeItems-total:
+			r.err = fmt.Errorf("more than %d items of zero size", avroMaxZeroSizeItems)
+			return
+		}
+		total += count
+		for i := int64(0); i < count && r.err == nil; i++ {
+			item()
+		}
+	}
+}
+
+// zeroSize значение типа кодируется нулём байт. seen защищает от
+// рекурсивных записей.
+func (t *avroType) zeroSize(seen map[*avroType]bool) bool {
+	switch t.kind {
+	case "null":
+		return true
+	case "fixed":
+		return t.size == 0
+	case "record":
+		if seen[t] {
+			return false
+		}
+		if seen == nil {
+			seen = make(map[*avroType]bool)
+		}
+		seen[t] = true
+		for _, f := range t.fields {
+			if !f.typ.zeroSize(seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// EncodeJSON кодирует значение, заданное в JSON, по схеме
+func (a *Avro) EncodeJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("encode avro: %w", err)
+	}
+	return a.Encode(v)
+}
+
+// Encode кодирует значение в бинарное представление. Записи задаются как
+// map[string]any, отсутствующие поля берутся из значений по умолчанию.
+func (a *Avro) Encode(v any) ([]byte, error) {
+	out, err := avroWrite(nil, a.root, v)
+	if err != nil {
+		return nil, fmt.Errorf("encode avro: %w", err)
+	}
+	return out, nil
+}
+
+func avroWrite(b []byte, t *avroType, v any) ([]byte, error) {
+	switch t.kind {
+	case "null":
+		if v != nil {
+			return nil, fmt.Errorf("expected null, got %T", v)
+		}
+		return b, nil
+	case "boolean":
+		x, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean, got %T", v)
+		}
+		if x {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case "int", "long":
+		x, ok := avroInt(v)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %v", t.kind, v)
+		}
+		return binary.AppendVarint(b, x), nil
+	case "float", "double":
+		x, ok := avroFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %v", t.kind, v)
+		}
+		if t.kind == "float" {
+			return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(x))), nil
+		}
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(x)), nil
+	case "string", "bytes":
+		var s []byte
+		switch x := v.(type) {
+		case string:
+			s = []byte(x)
+		case []byte:
+			s = x
+		default:
+			return nil, fmt.Errorf("expected %s, got %T", t.kind, v)
+		}
+		b = binary.AppendVarint(b, int64(len(s)))
+		return append(b, s...), nil
+	case "fixed":
+		s, ok := v.([]byte)
+		if !ok || len(s) != t.size {
+			return nil, fmt.Errorf("expected fixed %s of %d bytes", t.name, t.size)
+		}
+		return append(b, s...), nil
+	case "enum":
+		s, _ := v.(string)
+		for i, sym := range t.symbols {
+			if sym == s {
+				return binary.AppendVarint(b, int64(i)), nil
+			}
+		}
+		return nil, fmt.Errorf("enum %s: unknown symbol %v", t.name, v)
+	case "union":
+		for i, bt := range t.branches {
+			if out, err := avroWrite(binary.AppendVarint(b, int64(i)), bt, v); err == nil {
+				return out, nil
+			}
+		}
+		return nil, fmt.Errorf("no union branch matches %v", v)
+	case "record":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("record %s: expected object, got %T", t.name, v)
+		}
+		for _, f := range t.fields {
+			fv, ok := m[f.name]
+			if !ok {
+				if !f.hasDef {
+					return nil, fmt.Errorf("record %s: missing field %s", t.name, f.name)
+				}
+				fv = f.def
+			}
+			var err error
+			if b, err = avroWrite(b, f.typ, fv); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.name, f.name, err)
+			}
+		}
+		return b, nil
+	case "array":
+		items, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("expected array, got %T", v)
+		}
+		if len(items) > 0 {
+			b = binary.AppendVarint(b, int64(len(items)))
+			for _, it := range items {
+				var err error
+				if b, err = avroWrite(b, t.items, it); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(b, 0), nil
+	case "map":
+		m, ok := v.(map[string]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("expected map, got %T", v)
+		}
+		if len(m) > 0 {
+			b = binary.AppendVarint(b, int64(len(m)))
+			for k, mv := range m {
+				b = binary.AppendVarint(b, int64(len(k)))
+				b = append(b, k...)
+				var err error
+				if b, err = avroWrite(b, t.values, mv); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(b, 0), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t.kind)
+}
+
+func avroInt(v any) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int64:
+		return x, true
+	case json.Number:
+		n, err := x.Int64()
+		return n, err == nil
+	case float64:
+		return int64(x), x == math.Trunc(x)
+	}
+	return 0, false
+}
+
+func avroFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int64:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
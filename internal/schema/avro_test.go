@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAvro_InvalidEnum(t *testing.T) {
+	for _, src := range []string{
+		`{"type":"enum","name":"Status"}`,
+		`{"type":"enum","name":"Status","symbols":"A,B"}`,
+		`{"type":"enum","name":"Status","symbols":["A",1]}`,
+	} {
+		_, err := ParseAvro(src)
+		assert.ErrorContains(t, err, "enum Status", src)
+	}
+}
+
+func TestAvro_DecodeBlockCounts(t *testing.T) {
+	// zigzag varint: 2^61 элементов в блоке
+	huge := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x40}
+
+	nulls, err := ParseAvro(`{"type":"array","items":"null"}`)
+	require.NoError(t, err)
+	_, err = nulls.Decode(huge)
+	assert.ErrorContains(t, err, "items of zero size")
+
+	v, err := nulls.Decode([]byte{0x06, 0x00})
+	require.NoError(t, err)
+	assert.Len(t, v, 3)
+
+	longs, err := ParseAvro(`{"type":"array","items":"long"}`)
+	require.NoError(t, err)
+	_, err = longs.Decode(huge)
+	assert.ErrorContains(t, err, "exceeds")
+
+	maps, err := ParseAvro(`{"type":"map","values":"null"}`)
+	require.NoError(t, err)
+	_, err = maps.Decode(append([]byte{0x7f}, 0x02, 'a', 0x00))
+	assert.ErrorContains(t, err, "exceeds")
+}
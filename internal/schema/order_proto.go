@@ -0,0 +1,251 @@
+package schema
+
+import (
+	"fmt"
+	"l0/internal/domain"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Кодирование заказа по order.proto без сгенерированного кода: номера полей
+// ниже должны совпадать с order.proto
+
+// MarshalOrderProto кодирует заказ в Protobuf
+func MarshalOrderProto(o domain.Order) []byte {
+	var b []byte
+	b = appendString(b, 1, o.OrderUID)
+	b = appendString(b, 2, o.TrackNumber)
+	b = appendString(b, 3, o.Entry)
+	b = appendMessage(b, 4, marshalDelivery(o.Delivery))
+	b = appendMessage(b, 5, marshalPayment(o.Payment))
+	for _, it := range o.Items {
+		b = appendMessage(b, 6, marshalItem(it))
+	}
+	b = appendString(b, 7, o.Locale)
+	b = appendString(b, 8, o.InternalSignature)
+	b = appendString(b, 9, o.CustomerID)
+	b = appendString(b, 10, o.DeliveryService)
+	b = appendString(b, 11, o.Shardkey)
+	b = appendInt(b, 12, o.SmID)
+	return b
+}
+
+func marshalDelivery(d domain.Delivery) []byte {
+	var b []byte
+	b = appendString(b, 1, d.Name)
+	b = appendString(b, 2, d.Phone)
+	b = appendString(b, 3, d.Zip)
+	b = appendString(b, 4, d.City)
+	b = appendString(b, 5, d.Address)
+	b = appendString(b, 6, d.Region)
+	b = appendString(b, 7, d.Email)
+	return b
+}
+
+func marshalPayment(p domain.Payment) []byte {
+	var b []byte
+	b = appendString(b, 1, p.Transaction)
+	b = appendString(b, 2, p.Currency)
+	b = appendString(b, 3, p.Provider)
+	b = appendInt(b, 4, p.Amount)
+	b = appendInt(b, 5, p.PaymentDt)
+	b = appendString(b, 6, p.Bank)
+	b = appendInt(b, 7, p.DeliveryCost)
+	b = appendInt(b, 8, p.GoodsTotal)
+	b = appendInt(b, 9, p.CustomFee)
+	return b
+}
+
+func marshalItem(it domain.Items) []byte {
+	var b []byte
+	b = appendInt(b, 1, it.ChrtID)
+	b = appendInt(b, 2, it.Price)
+	b = appendString(b, 3, it.Rid)
+	b = appendString(b, 4, it.Name)
+	b = appendInt(b, 5, it.Sale)
+	b = appendString(b, 6, it.Size)
+	b = appendInt(b, 7, it.TotalPrice)
+	b = appendInt(b, 8, it.NmID)
+	b = appendString(b, 9, it.Brand)
+	return b
+}
+
+// UnmarshalOrderProto разбирает заказ из Protobuf. Неизвестные поля
+// пропускаются, как того требует proto3.
+func UnmarshalOrderProto(b []byte) (domain.Order, error) {
+	var o domain.Order
+	err := walk(b, func(num protowire.Number, v uint64, s []byte) error {
+		var err error
+		switch num {
+		case 1:
+			o.OrderUID = string(s)
+		case 2:
+			o.TrackNumber = string(s)
+		case 3:
+			o.Entry = string(s)
+		case 4:
+			o.Delivery, err = unmarshalDelivery(s)
+		case 5:
+			o.Payment, err = unmarshalPayment(s)
+		case 6:
+			var it domain.Items
+			if it, err = unmarshalItem(s); err == nil {
+				o.Items = append(o.Items, it)
+			}
+		case 7:
+			o.Locale = string(s)
+		case 8:
+			o.InternalSignature = string(s)
+		case 9:
+			o.CustomerID = string(s)
+		case 10:
+			o.DeliveryService = string(s)
+		case 11:
+			o.Shardkey = string(s)
+		case 12:
+			o.SmID = int(int64(v))
+		}
+		return err
+	})
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("unmarshal order: %w", err)
+	}
+	return o, nil
+}
+
+func unmarshalDelivery(b []byte) (domain.Delivery, error) {
+	var d domain.Delivery
+	err := walk(b, func(num protowire.Number, _ uint64, s []byte) error {
+		switch num {
+		case 1:
+			d.Name = string(s)
+		case 2:
+			d.Phone = string(s)
+		case 3:
+			d.Zip = string(s)
+		case 4:
+			d.City = string(s)
+		case 5:
+			d.Address = string(s)
+		case 6:
+			d.Region = string(s)
+		case 7:
+			d.Email = string(s)
+		}
+		return nil
+	})
+	return d, err
+}
+
+func unmarshalPayment(b []byte) (domain.Payment, error) {
+	var p domain.Payment
+	err := walk(b, func(num protowire.Number, v uint64, s []byte) error {
+		switch num {
+		case 1:
+			p.Transaction = string(s)
+		case 2:
+			p.Currency = string(s)
+		case 3:
+			p.Provider = string(s)
+		case 4:
+			p.Amount = int(int64(v))
+		case 5:
+			p.PaymentDt = int(int64(v))
+		case 6:
+			p.Bank = string(s)
+		case 7:
+			p.DeliveryCost = int(int64(v))
+		case 8:
+			p.GoodsTotal = int(int64(v))
+		case 9:
+			p.CustomFee = int(int64(v))
+		}
+		return nil
+	})
+	return p, err
+}
+
+func unmarshalItem(b []byte) (domain.Items, error) {
+	var it domain.Items
+	err := walk(b, func(num protowire.Number, v uint64, s []byte) error {
+		switch num {
+		case 1:
+			it.ChrtID = int(int64(v))
+		case 2:
+			it.Price = int(int64(v))
+		case 3:
+			it.Rid = string(s)
+		case 4:
+			it.Name = string(s)
+		case 5:
+			it.Sale = int(int64(v))
+		case 6:
+			it.Size = string(s)
+		case 7:
+			it.TotalPrice = int(int64(v))
+		case 8:
+			it.NmID = int(int64(v))
+		case 9:
+			it.Brand = string(s)
+		}
+		return nil
+	})
+	return it, err
+}
+
+// walk перебирает поля сообщения: для varint передаётся v, для
+// length-delimited — s. Поля других типов пропускаются.
+func walk(b []byte, fn func(num protowire.Number, v uint64, s []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v uint64
+		var s []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			s, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		if n < 0 {
+			return fmt.Errorf("field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, v, s); err != nil {
+			return fmt.Errorf("field %d: %w", num, err)
+		}
+	}
+	return nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendInt(b []byte, num protowire.Number, v int) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(v)))
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
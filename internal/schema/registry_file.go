@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// indexFile оглавление локального реестра
+const indexFile = "index.json"
+
+// FileRegistry локальный реестр схем в каталоге для разработки и тестов.
+// Оглавление index.json перечисляет версии схем и файлы с их текстом;
+// пути файлов указываются относительно каталога.
+type FileRegistry struct {
+	dir string
+
+	mu      sync.RWMutex
+	schemas []fileEntry
+}
+
+// fileEntry запись оглавления; текст схемы хранится в File
+type fileEntry struct {
+	ID      int    `json:"id"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Type    string `json:"schemaType,omitempty"`
+	File    string `json:"file"`
+	text    string
+}
+
+func (e fileEntry) schema() Schema {
+	return Schema{ID: e.ID, Subject: e.Subject, Version: e.Version, Type: e.Type, Schema: e.text}
+}
+
+type fileIndex struct {
+	Schemas []fileEntry `json:"schemas"`
+}
+
+// NewFileRegistry читает реестр из каталога; отсутствующий index.json
+// означает пустой реестр
+func NewFileRegistry(dir string) (*FileRegistry, error) {
+	r := &FileRegistry{dir: dir}
+
+	raw, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schema registry index: %w", err)
+	}
+	var idx fileIndex
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return nil, fmt.Errorf("parse schema registry index: %w", err)
+	}
+	for _, e := range idx.Schemas {
+		text, err := os.ReadFile(filepath.Join(dir, e.File))
+		if err != nil {
+			return nil, fmt.Errorf("read schema %d: %w", e.ID, err)
+		}
+		e.text = string(text)
+		r.schemas = append(r.schemas, e)
+	}
+	return r, nil
+}
+
+func (r *FileRegistry) SchemaByID(_ context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.schemas {
+		if e.ID == id {
+			return e.schema(), nil
+		}
+	}
+	return Schema{}, fmt.Errorf("schema %d: %w", id, ErrSchemaNotFound)
+}
+
+func (r *FileRegistry) Latest(_ context.Context, subject string) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest *fileEntry
+	for i := range r.schemas {
+		if e := &r.schemas[i]; e.Subject == subject && (latest == nil || e.Version > latest.Version) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return Schema{}, fmt.Errorf("subject %s: %w", subject, ErrSchemaNotFound)
+	}
+	return latest.schema(), nil
+}
+
+// Register сохраняет новую версию схемы в файл и обновляет оглавление
+func (r *FileRegistry) Register(_ context.Context, subject, schemaType, schema string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	maxID, version := 0, 1
+	for _, e := range r.schemas {
+		if e.Subject == subject && e.schema().SchemaType() == schemaType && e.text == schema {
+			return e.ID, nil
+		}
+		maxID = max(maxID, e.ID)
+		if e.Subject == subject {
+			version = max(version, e.Version+1)
+		}
+	}
+
+	ext := ".avsc"
+	switch schemaType {
+	case TypeProtobuf:
+		ext = ".proto"
+	case TypeJSON:
+		ext = ".json"
+	}
+	e := fileEntry{
+		ID:      maxID + 1,
+		Subject: subject,
+		Version: version,
+		Type:    schemaType,
+		File:    fmt.Sprintf("%s-v%d%s", subject, version, ext),
+		text:    schema,
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return 0, fmt.Errorf("register %s: %w", subject, err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, e.File), []byte(schema), 0o644); err != nil {
+		return 0, fmt.Errorf("register %s: %w", subject, err)
+	}
+
+	schemas := append(r.schemas, e)
+	if err := r.writeIndex(schemas); err != nil {
+		return 0, fmt.Errorf("register %s: %w", subject, err)
+	}
+	r.schemas = schemas
+	return e.ID, nil
+}
+
+// writeIndex записывает оглавление через временный файл, чтобы не оставить
+// его частично записанным
+func (r *FileRegistry) writeIndex(schemas []fileEntry) error {
+	raw, err := json.MarshalIndent(fileIndex{Schemas: schemas}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(r.dir, indexFile+".tmp")
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(r.dir, indexFile))
+}
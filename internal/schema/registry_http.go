@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentTypeRegistry тип тела запросов к Confluent Schema Registry
+const contentTypeRegistry = "application/vnd.schemaregistry.v1+json"
+
+// HTTPRegistry клиент Schema Registry по REST API Confluent. Схемы по ID
+// неизменяемы и кешируются.
+type HTTPRegistry struct {
+	baseURL string
+	client  *http.Client
+
+	mu   sync.RWMutex
+	byID map[int]Schema
+}
+
+func NewHTTPRegistry(baseURL string) *HTTPRegistry {
+	return &HTTPRegistry{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		byID:    make(map[int]Schema),
+	}
+}
+
+// registryError тело ответа реестра с ошибкой
+type registryError struct {
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (r *HTTPRegistry) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	s, ok := r.byID[id]
+	r.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	if err := r.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &s); err != nil {
+		return Schema{}, fmt.Errorf("schema %d: %w", id, err)
+	}
+	s.ID = id
+
+	r.mu.Lock()
+	r.byID[id] = s
+	r.mu.Unlock()
+	return s, nil
+}
+
+func (r *HTTPRegistry) Latest(ctx context.Context, subject string) (Schema, error) {
+	var s Schema
+	if err := r.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &s); err != nil {
+		return Schema{}, fmt.Errorf("subject %s: %w", subject, err)
+	}
+	return s, nil
+}
+
+func (r *HTTPRegistry) Register(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	req := Schema{Schema: schema}
+	if schemaType != TypeAvro {
+		req.Type = schemaType
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", req, &resp); err != nil {
+		return 0, fmt.Errorf("register %s: %w", subject, err)
+	}
+	return resp.ID, nil
+}
+
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentTypeRegistry)
+	if in != nil {
+		req.Header.Set("Content-Type", contentTypeRegistry)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var re registryError
+		_ = json.NewDecoder(resp.Body).Decode(&re)
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrSchemaNotFound, re.Message)
+		}
+		return fmt.Errorf("schema registry returned %d (%d): %s", resp.StatusCode, re.Code, re.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
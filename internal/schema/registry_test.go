@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRegistry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	r, err := NewFileRegistry(dir)
+	require.NoError(t, err)
+
+	id, err := r.Register(ctx, "orders-value", TypeAvro, OrderAvro)
+	require.NoError(t, err)
+	again, err := r.Register(ctx, "orders-value", TypeAvro, OrderAvro)
+	require.NoError(t, err)
+	assert.Equal(t, id, again, "same schema keeps its id")
+
+	v2 := `{"type":"record","name":"Order","fields":[{"name":"order_uid","type":"string"}]}`
+	id2, err := r.Register(ctx, "orders-value", TypeAvro, v2)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, id2)
+
+	// реестр читается заново из каталога
+	r, err = NewFileRegistry(dir)
+	require.NoError(t, err)
+	latest, err := r.Latest(ctx, "orders-value")
+	require.NoError(t, err)
+	assert.Equal(t, 2, latest.Version)
+	assert.Equal(t, v2, latest.Schema)
+
+	s, err := r.SchemaByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, OrderAvro, s.Schema)
+
+	_, err = r.SchemaByID(ctx, 100)
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
+
+func TestHTTPRegistry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		switch req.URL.Path {
+		case "/schemas/ids/7":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schema": OrderProto, "schemaType": TypeProtobuf})
+		case "/subjects/orders-value/versions":
+			var body Schema
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			assert.Empty(t, body.Type, "AVRO is sent without schemaType")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 3})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40403, "message": "Schema not found"})
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	r := NewHTTPRegistry(srv.URL + "/")
+
+	for range 2 {
+		s, err := r.SchemaByID(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, TypeProtobuf, s.SchemaType())
+		assert.Equal(t, 7, s.ID)
+	}
+	assert.Equal(t, 1, calls, "schemas by id are cached")
+
+	id, err := r.Register(ctx, "orders-value", TypeAvro, OrderAvro)
+	require.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	_, err = r.SchemaByID(ctx, 8)
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
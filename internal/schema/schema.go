@@ -0,0 +1,109 @@
+// Package schema описания заказа в бинарных форматах и клиент реестра схем,
+// совместимый с Confluent Schema Registry
+package schema
+
+import (
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Типы схем, как их называет реестр. Пустой тип означает AVRO.
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+	TypeJSON     = "JSON"
+)
+
+// OrderAvro схема заказа в формате Avro
+//
+//go:embed order.avsc
+var OrderAvro string
+
+// OrderProto описание заказа в формате Protobuf
+//
+//go:embed order.proto
+var OrderProto string
+
+// ErrSchemaNotFound схема или субъект отсутствуют в реестре
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// Schema версия схемы в реестре
+type Schema struct {
+	ID      int    `json:"id"`
+	Subject string `json:"subject,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Type    string `json:"schemaType,omitempty"`
+	Schema  string `json:"schema"`
+}
+
+// SchemaType тип схемы, AVRO если не указан
+func (s Schema) SchemaType() string {
+	if s.Type == "" {
+		return TypeAvro
+	}
+	return s.Type
+}
+
+// Registry реестр схем, реализуется HTTPRegistry и FileRegistry
+type Registry interface {
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+	Latest(ctx context.Context, subject string) (Schema, error)
+	// Register регистрирует схему и возвращает её ID; повторная регистрация
+	// той же схемы возвращает существующий ID
+	Register(ctx context.Context, subject, schemaType, schema string) (int, error)
+}
+
+// ValueSubject субъект схемы значений топика (TopicNameStrategy)
+func ValueSubject(topic string) string {
+	return topic + "-value"
+}
+
+// wireMagic первый байт сообщения в формате реестра: за ним следует
+// 4-байтовый ID схемы в big-endian
+const wireMagic = 0
+
+// SplitWire отделяет ID схемы от тела сообщения в формате реестра.
+// ok == false, если сообщение в этом формате не записано.
+func SplitWire(b []byte) (id int, payload []byte, ok bool) {
+	if len(b) < 5 || b[0] != wireMagic {
+		return 0, b, false
+	}
+	return int(binary.BigEndian.Uint32(b[1:5])), b[5:], true
+}
+
+// AppendWire добавляет заголовок формата реестра с ID схемы
+func AppendWire(dst []byte, id int) []byte {
+	dst = append(dst, wireMagic)
+	return binary.BigEndian.AppendUint32(dst, uint32(id))
+}
+
+// SkipMessageIndexes пропускает индексы сообщения, которые Protobuf
+// сериализатор реестра пишет после ID схемы. Индексы указывают тип
+// сообщения в .proto файле; поддерживается только первый тип.
+func SkipMessageIndexes(b []byte) ([]byte, error) {
+	count, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return nil, fmt.Errorf("read message indexes: %w", protowire.ParseError(n))
+	}
+	b = b[n:]
+	// одиночный 0 — сокращённая запись индекса [0]
+	if count == 0 {
+		return b, nil
+	}
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		idx, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, fmt.Errorf("read message indexes: %w", protowire.ParseError(n))
+		}
+		if idx != 0 {
+			return nil, fmt.Errorf("nested message types are not supported")
+		}
+		b = b[n:]
+	}
+	return b, nil
+}
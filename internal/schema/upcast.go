@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Версии тела заказа
+const (
+	// OrderVersionLegacy исходная модель поставщика (model.json): без версии,
+	// с полями, которых нет в domain.Order
+	OrderVersionLegacy = 1
+	// OrderVersion текущая версия, совпадает с JSON-представлением domain.Order
+	OrderVersion = 2
+)
+
+// VersionField поле тела JSON с версией схемы
+const VersionField = "schema_version"
+
+var (
+	// ErrFutureVersion версия новее, чем умеет читать сервис
+	ErrFutureVersion = errors.New("unsupported future schema version")
+	// ErrUnknownVersion версия, для которой нет преобразования
+	ErrUnknownVersion = errors.New("unknown schema version")
+)
+
+// Upcaster преобразует документ версии N в версию N+1
+type Upcaster func(doc map[string]any) (map[string]any, error)
+
+// Upcasters цепочка преобразований старых версий документа в текущую
+type Upcasters struct {
+	current int
+	steps   map[int]Upcaster
+}
+
+func NewUpcasters(current int) *Upcasters {
+	return &Upcasters{current: current, steps: make(map[int]Upcaster)}
+}
+
+// Register задаёт преобразование версии from в from+1
+func (u *Upcasters) Register(from int, up Upcaster) {
+	u.steps[from] = up
+}
+
+// Current версия, к которой приводятся документы
+func (u *Upcasters) Current() int {
+	return u.current
+}
+
+// Upcast приводит документ версии version к текущей версии
+func (u *Upcasters) Upcast(version int, doc map[string]any) (map[string]any, error) {
+	if version > u.current {
+		return nil, fmt.Errorf("%w %d, latest is %d", ErrFutureVersion, version, u.current)
+	}
+	for v := version; v < u.current; v++ {
+		up, ok := u.steps[v]
+		if !ok {
+			return nil, fmt.Errorf("%w %d", ErrUnknownVersion, v)
+		}
+		var err error
+		if doc, err = up(doc); err != nil {
+			return nil, fmt.Errorf("upcast from version %d: %w", v, err)
+		}
+	}
+	delete(doc, VersionField)
+	return doc, nil
+}
+
+// OrderUpcasters преобразования всех известных версий заказа
+func OrderUpcasters() *Upcasters {
+	u := NewUpcasters(OrderVersion)
+	u.Register(OrderVersionLegacy, upcastOrderV1)
+	return u
+}
+
+// upcastOrderV1 убирает поля модели поставщика, которые сервис не хранит:
+// дату и шард заказа, request_id оплаты и служебные поля позиций
+func upcastOrderV1(doc map[string]any) (map[string]any, error) {
+	delete(doc, "date_created")
+	delete(doc, "oof_shard")
+	if payment, ok := doc["payment"].(map[string]any); ok {
+		delete(payment, "request_id")
+	}
+	if items, ok := doc["items"].([]any); ok {
+		for _, it := range items {
+			if item, ok := it.(map[string]any); ok {
+				delete(item, "track_number")
+				delete(item, "status")
+			}
+		}
+	}
+	return doc, nil
+}
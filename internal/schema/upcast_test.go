@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"l0/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Каждая версия заказа хранится в testdata/orders/v<N>.json. Все версии
+// должны приводиться к одному и тому же заказу; новая версия схемы требует
+// новой фикстуры.
+func TestOrderUpcasters_Fixtures(t *testing.T) {
+	upcasters := OrderUpcasters()
+
+	var want domain.Order
+	for v := OrderVersionLegacy; v <= OrderVersion; v++ {
+		t.Run(fmt.Sprintf("v%d", v), func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("testdata", "orders", fmt.Sprintf("v%d.json", v)))
+			require.NoError(t, err, "fixture for version %d is missing", v)
+
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			var doc map[string]any
+			require.NoError(t, dec.Decode(&doc))
+
+			doc, err = upcasters.Upcast(v, doc)
+			require.NoError(t, err)
+
+			// после приведения в документе не остаётся полей вне domain.Order
+			out, err := json.Marshal(doc)
+			require.NoError(t, err)
+			strict := json.NewDecoder(bytes.NewReader(out))
+			strict.DisallowUnknownFields()
+			var got domain.Order
+			require.NoError(t, strict.Decode(&got))
+
+			if v == OrderVersionLegacy {
+				want = got
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+// custom_fee модели поставщика сохраняется и входит в проверку amount
+func TestOrderUpcasters_V1CustomFee(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "orders", "v1.json"))
+	require.NoError(t, err)
+
+	decode := func(fee, amount int) domain.Order {
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(raw, &doc))
+		payment := doc["payment"].(map[string]any)
+		payment["custom_fee"], payment["amount"] = fee, amount
+
+		doc, err := OrderUpcasters().Upcast(OrderVersionLegacy, doc)
+		require.NoError(t, err)
+		out, err := json.Marshal(doc)
+		require.NoError(t, err)
+		var o domain.Order
+		require.NoError(t, json.Unmarshal(out, &o))
+		return o
+	}
+
+	o := decode(250, 2067)
+	assert.Equal(t, 250, o.Payment.CustomFee)
+	assert.NoError(t, domain.ValidateOrder(o))
+
+	var verr *domain.ValidationError
+	require.ErrorAs(t, domain.ValidateOrder(decode(250, 1817)), &verr)
+	assert.Equal(t, domain.RuleAmount, verr.Violations[0].Rule)
+	assert.Equal(t, 2067, verr.Violations[0].Expected)
+}
+
+func TestUpcasters_RejectsUnknownVersions(t *testing.T) {
+	upcasters := OrderUpcasters()
+
+	_, err := upcasters.Upcast(OrderVersion+1, map[string]any{})
+	assert.ErrorIs(t, err, ErrFutureVersion)
+
+	_, err = upcasters.Upcast(0, map[string]any{})
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
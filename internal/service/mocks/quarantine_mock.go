@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: quarantine.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	domain "l0/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockQuarantineRepository is a mock of QuarantineRepository interface.
+type MockQuarantineRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuarantineRepositoryMockRecorder
+}
+
+// MockQuarantineRepositoryMockRecorder is the mock recorder for MockQuarantineRepository.
+type MockQuarantineRepositoryMockRecorder struct {
+	mock *MockQuarantineRepository
+}
+
+// NewMockQuarantineRepository creates a new mock instance.
+func NewMockQuarantineRepository(ctrl *gomock.Controller) *MockQuarantineRepository {
+	mock := &MockQuarantineRepository{ctrl: ctrl}
+	mock.recorder = &MockQuarantineRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuarantineRepository) EXPECT() *MockQuarantineRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimQuarantined mocks base method.
+func (m *MockQuarantineRepository) ClaimQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimQuarantined", ctx, id)
+	ret0, _ := ret[0].(domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimQuarantined indicates an expected call of ClaimQuarantined.
+func (mr *MockQuarantineRepositoryMockRecorder) ClaimQuarantined(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQuarantined", reflect.TypeOf((*MockQuarantineRepository)(nil).ClaimQuarantined), ctx, id)
+}
+
+// DiscardQuarantined mocks base method.
+func (m *MockQuarantineRepository) DiscardQuarantined(ctx context.Context, id int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscardQuarantined", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DiscardQuarantined indicates an expected call of DiscardQuarantined.
+func (mr *MockQuarantineRepositoryMockRecorder) DiscardQuarantined(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardQuarantined", reflect.TypeOf((*MockQuarantineRepository)(nil).DiscardQuarantined), ctx, id, reason)
+}
+
+// GetQuarantined mocks base method.
+func (m *MockQuarantineRepository) GetQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuarantined", ctx, id)
+	ret0, _ := ret[0].(domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuarantined indicates an expected call of GetQuarantined.
+func (mr *MockQuarantineRepositoryMockRecorder) GetQuarantined(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuarantined", reflect.TypeOf((*MockQuarantineRepository)(nil).GetQuarantined), ctx, id)
+}
+
+// ListQuarantined mocks base method.
+func (m *MockQuarantineRepository) ListQuarantined(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQuarantined", ctx, f)
+	ret0, _ := ret[0].([]domain.QuarantinedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQuarantined indicates an expected call of ListQuarantined.
+func (mr *MockQuarantineRepositoryMockRecorder) ListQuarantined(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQuarantined", reflect.TypeOf((*MockQuarantineRepository)(nil).ListQuarantined), ctx, f)
+}
+
+// MarkQuarantineReplayed mocks base method.
+func (m *MockQuarantineRepository) MarkQuarantineReplayed(ctx context.Context, id int64, orderID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkQuarantineReplayed", ctx, id, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkQuarantineReplayed indicates an expected call of MarkQuarantineReplayed.
+func (mr *MockQuarantineRepositoryMockRecorder) MarkQuarantineReplayed(ctx, id, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkQuarantineReplayed", reflect.TypeOf((*MockQuarantineRepository)(nil).MarkQuarantineReplayed), ctx, id, orderID)
+}
+
+// ReleaseQuarantined mocks base method.
+func (m *MockQuarantineRepository) ReleaseQuarantined(ctx context.Context, id int64, stage, reason string, violations []domain.Violation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseQuarantined", ctx, id, stage, reason, violations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseQuarantined indicates an expected call of ReleaseQuarantined.
+func (mr *MockQuarantineRepositoryMockRecorder) ReleaseQuarantined(ctx, id, stage, reason, violations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseQuarantined", reflect.TypeOf((*MockQuarantineRepository)(nil).ReleaseQuarantined), ctx, id, stage, reason, violations)
+}
+
+// UpdateQuarantinedPayload mocks base method.
+func (m *MockQuarantineRepository) UpdateQuarantinedPayload(ctx context.Context, id int64, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuarantinedPayload", ctx, id, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateQuarantinedPayload indicates an expected call of UpdateQuarantinedPayload.
+func (mr *MockQuarantineRepositoryMockRecorder) UpdateQuarantinedPayload(ctx, id, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuarantinedPayload", reflect.TypeOf((*MockQuarantineRepository)(nil).UpdateQuarantinedPayload), ctx, id, payload)
+}
+
+// MockIngester is a mock of Ingester interface.
+type MockIngester struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngesterMockRecorder
+}
+
+// MockIngesterMockRecorder is the mock recorder for MockIngester.
+type MockIngesterMockRecorder struct {
+	mock *MockIngester
+}
+
+// NewMockIngester creates a new mock instance.
+func NewMockIngester(ctrl *gomock.Controller) *MockIngester {
+	mock := &MockIngester{ctrl: ctrl}
+	mock.recorder = &MockIngesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngester) EXPECT() *MockIngesterMockRecorder {
+	return m.recorder
+}
+
+// Ingest mocks base method.
+func (m *MockIngester) Ingest(ctx context.Context, topic string, headers map[string]string, payload []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ingest", ctx, topic, headers, payload)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ingest indicates an expected call of Ingest.
+func (mr *MockIngesterMockRecorder) Ingest(ctx, topic, headers, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ingest", reflect.TypeOf((*MockIngester)(nil).Ingest), ctx, topic, headers, payload)
+}
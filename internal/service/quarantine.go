@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"l0/internal/domain"
+	"l0/internal/kafka"
+	"l0/pkg/e"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source=quarantine.go -destination=mocks/quarantine_mock.go
+
+// QuarantineRepository хранилище карантина, реализуется pg.Postgres
+type QuarantineRepository interface {
+	ListQuarantined(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error)
+	GetQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error)
+	UpdateQuarantinedPayload(ctx context.Context, id int64, payload []byte) error
+	ClaimQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error)
+	ReleaseQuarantined(ctx context.Context, id int64, stage, reason string, violations []domain.Violation) error
+	MarkQuarantineReplayed(ctx context.Context, id int64, orderID int) error
+	DiscardQuarantined(ctx context.Context, id int64, reason string) error
+}
+
+// Ingester обработка тела сообщения обработчиком его топика тем же путём,
+// что и сообщений из Kafka, реализуется kafka.Handlers. Возвращает ID
+// заказа или 0 для остальных топиков.
+type Ingester interface {
+	Ingest(ctx context.Context, topic string, headers map[string]string, payload []byte) (int, error)
+}
+
+// resolveTimeout время на запись итога повторной обработки. Итог пишется и
+// после отмены запроса, иначе сообщение останется в replaying.
+const resolveTimeout = 5 * time.Second
+
+// ReplayResult итог повторной обработки одного сообщения
+type ReplayResult struct {
+	ID         int64                   `json:"id"`
+	Status     domain.QuarantineStatus `json:"status,omitempty"`
+	OrderID    int                     `json:"order_id,omitempty"`
+	Stage      string                  `json:"stage,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	Violations []domain.Violation      `json:"violations,omitempty"`
+}
+
+// Quarantine просмотр, исправление и повторная обработка сообщений карантина
+type Quarantine struct {
+	repo     QuarantineRepository
+	ingester Ingester
+	logger   *slog.Logger
+}
+
+func NewQuarantine(logger *slog.Logger, repo QuarantineRepository, ingester Ingester) *Quarantine {
+	return &Quarantine{
+		repo:     repo,
+		ingester: ingester,
+		logger:   logger,
+	}
+}
+
+func (q *Quarantine) List(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error) {
+	return q.repo.ListQuarantined(ctx, f)
+}
+
+func (q *Quarantine) Get(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	return q.repo.GetQuarantined(ctx, id)
+}
+
+// EditPayload заменяет тело сообщения и возвращает обновлённую запись
+func (q *Quarantine) EditPayload(ctx context.Context, id int64, payload []byte) (domain.QuarantinedMessage, error) {
+	if err := q.repo.UpdateQuarantinedPayload(ctx, id, payload); err != nil {
+		return domain.QuarantinedMessage{}, e.Wrap("service.Quarantine.EditPayload", err)
+	}
+	q.logger.Info("Quarantined message edited", slog.Int64("id", id))
+	return q.repo.GetQuarantined(ctx, id)
+}
+
+// Replay повторно обрабатывает сообщение. Неудача обработки не считается
+// ошибкой: сообщение остаётся в карантине с новой причиной, а результат
+// описывает, что пошло не так.
+func (q *Quarantine) Replay(ctx context.Context, id int64) (ReplayResult, error) {
+	m, err := q.repo.ClaimQuarantined(ctx, id)
+	if err != nil {
+		return ReplayResult{}, e.Wrap("service.Quarantine.Replay", err)
+	}
+
+	orderID, err := q.ingester.Ingest(ctx, m.Topic, m.Headers, []byte(m.Payload))
+	resolveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resolveTimeout)
+	defer cancel()
+	if err != nil {
+		failure := &kafka.Failure{Stage: kafka.StagePersist, Err: err}
+		errors.As(err, &failure)
+		if releaseErr := q.repo.ReleaseQuarantined(resolveCtx, id, failure.Stage, failure.Err.Error(), failure.Violations); releaseErr != nil {
+			return ReplayResult{}, e.Wrap("service.Quarantine.Replay", errors.Join(err, releaseErr))
+		}
+		q.logger.Warn("Quarantined message replay failed", slog.Int64("id", id), slog.String("stage", failure.Stage), slog.String("error", failure.Err.Error()))
+		return ReplayResult{
+			ID:         id,
+			Status:     domain.QuarantinePending,
+			Stage:      failure.Stage,
+			Error:      failure.Err.Error(),
+			Violations: failure.Violations,
+		}, nil
+	}
+
+	if err := q.repo.MarkQuarantineReplayed(resolveCtx, id, orderID); err != nil {
+		return ReplayResult{}, e.Wrap("service.Quarantine.Replay", err)
+	}
+	q.logger.Info("Quarantined message replayed", slog.Int64("id", id), slog.Int("order_id", orderID))
+	return ReplayResult{ID: id, Status: domain.QuarantineReplayed, OrderID: orderID}, nil
+}
+
+// ReplayMany повторно обрабатывает сообщения по очереди; ошибка по одному
+// сообщению не прерывает обработку остальных
+func (q *Quarantine) ReplayMany(ctx context.Context, ids []int64) []ReplayResult {
+	results := make([]ReplayResult, 0, len(ids))
+	for _, id := range ids {
+		res, err := q.Replay(ctx, id)
+		if err != nil {
+			res = ReplayResult{ID: id, Error: err.Error()}
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Discard отбрасывает сообщение, сохраняя причину
+func (q *Quarantine) Discard(ctx context.Context, id int64, reason string) error {
+	if err := q.repo.DiscardQuarantined(ctx, id, reason); err != nil {
+		return e.Wrap("service.Quarantine.Discard", err)
+	}
+	q.logger.Info("Quarantined message discarded", slog.Int64("id", id), slog.String("reason", reason))
+	return nil
+}
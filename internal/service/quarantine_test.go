@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"l0/internal/domain"
+	"l0/internal/kafka"
+	mock_service "l0/internal/service/mocks"
+	"log/slog"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarantine_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_service.NewMockQuarantineRepository(ctrl)
+	ingester := mock_service.NewMockIngester(ctrl)
+	q := NewQuarantine(slog.Default(), repo, ingester)
+	ctx := context.Background()
+
+	// неудача: сообщение возвращается в ожидание с новой причиной, даже если
+	// клиент отключился во время обработки
+	violations := []domain.Violation{{Field: "entry", Rule: "required"}}
+	reqCtx, disconnect := context.WithCancel(ctx)
+	defer disconnect()
+	repo.EXPECT().ClaimQuarantined(reqCtx, int64(1)).Return(domain.QuarantinedMessage{ID: 1, Payload: `{}`}, nil)
+	ingester.EXPECT().Ingest(reqCtx, gomock.Any(), gomock.Any(), []byte(`{}`)).DoAndReturn(
+		func(context.Context, string, map[string]string, []byte) (int, error) {
+			disconnect()
+			return 0, &kafka.Failure{Stage: kafka.StageValidate, Err: errors.New("invalid"), Violations: violations}
+		})
+	repo.EXPECT().ReleaseQuarantined(gomock.Any(), int64(1), kafka.StageValidate, "invalid", violations).DoAndReturn(
+		func(ctx context.Context, _ int64, _, _ string, _ []domain.Violation) error {
+			return ctx.Err()
+		})
+
+	res, err := q.Replay(reqCtx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuarantinePending, res.Status)
+	assert.Equal(t, violations, res.Violations)
+
+	// успех: сообщение отмечается обработанным
+	repo.EXPECT().ClaimQuarantined(ctx, int64(2)).Return(domain.QuarantinedMessage{ID: 2, Topic: "orders", Payload: `{"order_uid":"x"}`}, nil)
+	ingester.EXPECT().Ingest(ctx, "orders", gomock.Any(), gomock.Any()).Return(42, nil)
+	repo.EXPECT().MarkQuarantineReplayed(gomock.Any(), int64(2), 42).Return(nil)
+
+	res, err = q.Replay(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuarantineReplayed, res.Status)
+	assert.Equal(t, 42, res.OrderID)
+}
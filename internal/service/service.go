@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"l0/internal/cache"
 	"l0/internal/domain"
 	"l0/pkg/e"
 	"log/slog"
@@ -13,30 +14,50 @@ import (
 type OrderRepository interface {
 	GetByID(ctx context.Context, id int) (domain.Order, error)
 	Create(ctx context.Context, order domain.Order) (int, error)
+	CreateMany(ctx context.Context, orders []domain.Order) ([]int, error)
 }
 
-// Cache интерфейс кеша
+// Cache интерфейс кеша заказов, реализуется cache.Cache[domain.Order]
 type Cache interface {
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
-	Get(ctx context.Context, key string, dest *domain.Order) (string, error)
+	Set(ctx context.Context, key string, value domain.Order, opts ...cache.SetOption) error
+	Get(ctx context.Context, key string) (domain.Order, error)
+	Delete(ctx context.Context, keys ...string) (int64, error)
+}
+
+// EventPublisher публикация доменных событий, реализуется kafka.EventProducer
+type EventPublisher interface {
+	Publish(ctx context.Context, ev domain.Event) error
 }
 
 // Service бизнес-логика для заказов
 type Service struct {
 	repo   OrderRepository
 	cache  Cache
+	events EventPublisher
 	logger *slog.Logger
 }
 
-// NewService создаёт новый сервисный слой
-func NewService(logger *slog.Logger, repo OrderRepository, cache Cache) *Service {
+// NewService создаёт новый сервисный слой. events может быть nil,
+// тогда события не публикуются.
+func NewService(logger *slog.Logger, repo OrderRepository, cache Cache, events EventPublisher) *Service {
 	return &Service{
 		repo:   repo,
 		cache:  cache,
+		events: events,
 		logger: logger,
 	}
 }
 
+// GetByID получает заказ по ID, позволяет использовать сервис как хранилище хендлеров
+func (s *Service) GetByID(ctx context.Context, id int) (domain.Order, error) {
+	return s.GetOrderByID(ctx, id)
+}
+
+// Create создаёт заказ, позволяет использовать сервис как хранилище хендлеров
+func (s *Service) Create(ctx context.Context, order domain.Order) (int, error) {
+	return s.CreateOrder(ctx, order)
+}
+
 // GetOrderByID получает заказ по его ID из репозитория
 func (s *Service) GetOrderByID(ctx context.Context, id int) (domain.Order, error) {
 	return s.repo.GetByID(ctx, id)
@@ -49,9 +70,37 @@ func (s *Service) CreateOrder(ctx context.Context, order domain.Order) (int, err
 		s.logger.Error("Failed to create order", slog.String("error", err.Error()))
 		return 0, e.Wrap("service.CreateOrder", err)
 	}
+	s.publishCreated(ctx, id, order)
 	return id, nil
 }
 
+// CreateOrders сохраняет заказы одной транзакцией
+func (s *Service) CreateOrders(ctx context.Context, orders []domain.Order) ([]int, error) {
+	ids, err := s.repo.CreateMany(ctx, orders)
+	if err != nil {
+		s.logger.Error("Failed to create orders", slog.Int("count", len(orders)), slog.String("error", err.Error()))
+		return nil, e.Wrap("service.CreateOrders", err)
+	}
+	for i, id := range ids {
+		s.publishCreated(ctx, id, orders[i])
+	}
+	return ids, nil
+}
+
+// publishCreated публикует событие о сохранённом заказе. Заказ уже сохранён,
+// поэтому ошибка публикации только логируется.
+func (s *Service) publishCreated(ctx context.Context, id int, order domain.Order) {
+	if s.events == nil {
+		return
+	}
+	if err := s.events.Publish(ctx, domain.NewOrderCreatedEvent(id, order)); err != nil {
+		s.logger.Error("Failed to publish order event",
+			slog.String("type", domain.EventOrderCreated),
+			slog.String("order_uid", order.OrderUID),
+			slog.String("error", err.Error()))
+	}
+}
+
 // MarshalOrderJSON преобразует заказ в JSON строку
 func (s *Service) MarshalOrderJSON(order domain.Order) (string, error) {
 	b, err := json.Marshal(order)
@@ -61,12 +110,12 @@ func (s *Service) MarshalOrderJSON(order domain.Order) (string, error) {
 	return string(b), nil
 }
 
-// CacheSet сохраняет значение в кеш
-func (s *Service) CacheSet(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return s.cache.Set(ctx, key, value, expiration)
+// CacheSet сохраняет заказ в кеш
+func (s *Service) CacheSet(ctx context.Context, key string, value domain.Order, expiration time.Duration) error {
+	return s.cache.Set(ctx, key, value, cache.WithTTL(expiration))
 }
 
-// CacheGet получает значение из кеша
-func (s *Service) CacheGet(ctx context.Context, key string, dest *domain.Order) (string, error) {
-	return s.cache.Get(ctx, key, dest)
+// CacheGet получает заказ из кеша
+func (s *Service) CacheGet(ctx context.Context, key string) (domain.Order, error) {
+	return s.cache.Get(ctx, key)
 }
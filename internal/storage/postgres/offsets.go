@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"context"
+	"l0/internal/domain"
+	"l0/pkg/e"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// insertProcessed отмечает сообщения партиции обработанными
+const insertProcessed = `INSERT INTO consumer_processed (consumer_group, topic, partition, "offset")
+	SELECT $1, $2, $3, unnest($4::bigint[]) ON CONFLICT DO NOTHING`
+
+// execer пул соединений или транзакция
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// insertOffsets отмечает обработанными сообщения одной партиции
+func insertOffsets(ctx context.Context, db execer, offsets []domain.MessageOffset) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+	values := make([]int64, len(offsets))
+	for i, o := range offsets {
+		values[i] = o.Offset
+	}
+	first := offsets[0]
+	_, err := db.Exec(ctx, insertProcessed, first.Group, first.Topic, first.Partition, values)
+	return err
+}
+
+// saveMessageOffset отмечает сообщения из ctx обработанными в транзакции tx
+func saveMessageOffset(ctx context.Context, tx pgx.Tx) error {
+	if err := insertOffsets(ctx, tx, domain.MessageOffsetsFrom(ctx)); err != nil {
+		return e.Wrap("storage.pg.saveMessageOffset", err)
+	}
+	return nil
+}
+
+// Offsets позиции партиций топика для группы и обработанные сообщения после
+// них. Партиция без позиции, но с отметками сообщений получает Next = -1.
+func (p *Postgres) Offsets(ctx context.Context, group, topic string) (map[int32]domain.PartitionOffsets, error) {
+	rows, err := p.pool.Query(ctx, `SELECT partition, next_offset FROM consumer_offsets
+		WHERE consumer_group = $1 AND topic = $2`, group, topic)
+	if err != nil {
+		return nil, e.Wrap("storage.pg.Offsets", err)
+	}
+	defer rows.Close()
+
+	offsets := make(map[int32]domain.PartitionOffsets)
+	for rows.Next() {
+		var partition int32
+		var next int64
+		if err := rows.Scan(&partition, &next); err != nil {
+			return nil, e.Wrap("storage.pg.Offsets.Scan", err)
+		}
+		offsets[partition] = domain.PartitionOffsets{Next: next}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap("storage.pg.Offsets", err)
+	}
+
+	rows, err = p.pool.Query(ctx, `SELECT m.partition, m."offset" FROM consumer_processed m
+		LEFT JOIN consumer_offsets o USING (consumer_group, topic, partition)
+		WHERE m.consumer_group = $1 AND m.topic = $2 AND m."offset" >= COALESCE(o.next_offset, 0)
+		ORDER BY m.partition, m."offset"`, group, topic)
+	if err != nil {
+		return nil, e.Wrap("storage.pg.Offsets.Processed", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var partition int32
+		var offset int64
+		if err := rows.Scan(&partition, &offset); err != nil {
+			return nil, e.Wrap("storage.pg.Offsets.Processed.Scan", err)
+		}
+		po, ok := offsets[partition]
+		if !ok {
+			po.Next = -1
+		}
+		po.Processed = append(po.Processed, offset)
+		offsets[partition] = po
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap("storage.pg.Offsets.Processed", err)
+	}
+	return offsets, nil
+}
+
+// SaveOffset отмечает сообщение обработанным без данных, например после
+// отправки в DLQ
+func (p *Postgres) SaveOffset(ctx context.Context, o domain.MessageOffset) error {
+	if err := insertOffsets(ctx, p.pool, []domain.MessageOffset{o}); err != nil {
+		return e.Wrap("storage.pg.SaveOffset", err)
+	}
+	return nil
+}
+
+// AdvanceOffset сдвигает позицию партиции только вперёд и удаляет отметки
+// сообщений до неё
+func (p *Postgres) AdvanceOffset(ctx context.Context, group, topic string, partition int32, next int64) error {
+	_, err := p.pool.Exec(ctx, `WITH pos AS (
+			INSERT INTO consumer_offsets (consumer_group, topic, partition, next_offset)
+			VALUES ($1, $2, $3, $4) ON CONFLICT (consumer_group, topic, partition) DO UPDATE
+			SET next_offset = GREATEST(consumer_offsets.next_offset, excluded.next_offset), updated_at = now()
+			RETURNING next_offset)
+		DELETE FROM consumer_processed WHERE consumer_group = $1 AND topic = $2 AND partition = $3
+			AND "offset" < (SELECT next_offset FROM pos)`, group, topic, partition, next)
+	if err != nil {
+		return e.Wrap("storage.pg.AdvanceOffset", err)
+	}
+	return nil
+}
+
+// ResetOffset переносит позицию партиции на next в любую сторону и снимает
+// отметки всех её сообщений, чтобы они были обработаны заново
+func (p *Postgres) ResetOffset(ctx context.Context, group, topic string, partition int32, next int64) error {
+	_, err := p.pool.Exec(ctx, `WITH processed AS (
+			DELETE FROM consumer_processed WHERE consumer_group = $1 AND topic = $2 AND partition = $3)
+		INSERT INTO consumer_offsets (consumer_group, topic, partition, next_offset)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (consumer_group, topic, partition) DO UPDATE
+		SET next_offset = excluded.next_offset, updated_at = now()`, group, topic, partition, next)
+	if err != nil {
+		return e.Wrap("storage.pg.ResetOffset", err)
+	}
+	return nil
+}
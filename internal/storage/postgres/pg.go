@@ -5,24 +5,48 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"l0/internal/cache"
 	"l0/internal/config"
 	"l0/internal/domain"
-	"l0/internal/storage/redis"
+	"l0/internal/health"
 	"l0/pkg/e"
 	"log"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/singleflight"
 )
 
+// refreshTimeout ограничивает фоновое обновление устаревшей записи кэша
+const refreshTimeout = 5 * time.Second
+
+// loadTimeout ограничивает общую загрузку заказа, которую ждут несколько запросов
+const loadTimeout = 5 * time.Second
+
+// OrderCache кэш заказов, через который читаются заказы
+type OrderCache interface {
+	GetWithMeta(ctx context.Context, key string) (domain.Order, cache.Meta, error)
+	Set(ctx context.Context, key string, value domain.Order, opts ...cache.SetOption) error
+	TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error)
+	Unlock(ctx context.Context, key, token string) error
+}
+
 type Postgres struct {
-	pool        *pgxpool.Pool
-	redisClient *redis.Redis
-	logger      *slog.Logger
+	pool       *pgxpool.Pool
+	orderCache OrderCache
+	logger     *slog.Logger
+
+	// loads объединяет одновременные загрузки одного заказа из БД
+	loads singleflight.Group
+	// refreshing ключи, которые сейчас обновляются в фоне этим экземпляром
+	refreshing sync.Map
+	refreshWG  sync.WaitGroup
 }
 
-func NewPostgres(ctx context.Context, cfg *config.Config, logger *slog.Logger, redisClient *redis.Redis) (*Postgres, error) {
+func NewPostgres(ctx context.Context, cfg *config.Config, logger *slog.Logger, orderCache OrderCache) (*Postgres, error) {
 	connectionString := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Postgres.Host,
@@ -48,29 +72,107 @@ func NewPostgres(ctx context.Context, cfg *config.Config, logger *slog.Logger, r
 	}
 
 	return &Postgres{
-		pool:        pool,
-		redisClient: redisClient,
-		logger:      logger,
+		pool:       pool,
+		orderCache: orderCache,
+		logger:     logger,
 	}, nil
 }
 
 func (p *Postgres) GetByID(ctx context.Context, id int) (domain.Order, error) {
-	var o domain.Order
-	var payment_id_fk int64
-	key := fmt.Sprintf("order: %v", id)
-	_, err := p.redisClient.Get(ctx, key, &o)
+	key := cache.OrderKey(id)
+	o, meta, err := p.orderCache.GetWithMeta(ctx, key)
 	if err != nil {
 		log.Println("Failed get order from cache")
 	} else {
 		log.Printf("Order from redis cache")
+		if meta.Stale {
+			p.refreshAsync(id, key)
+		}
+		return o, nil
+	}
+
+	return p.loadAndCache(ctx, id, key)
+}
+
+// loadAndCache загружает заказ из БД и кладёт его в кэш; одновременные
+// запросы одного заказа выполняют загрузку один раз. Загрузка не зависит от
+// отмены запроса, который её начал: иначе её результат потеряли бы и
+// остальные ожидающие.
+func (p *Postgres) loadAndCache(ctx context.Context, id int, key string) (domain.Order, error) {
+	ch := p.loads.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		o, err := p.loadOrder(loadCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		p.cacheOrder(loadCtx, key, o)
 		return o, nil
+	})
+	select {
+	case <-ctx.Done():
+		return domain.Order{}, e.Wrap("storage.pg.loadAndCache", ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return domain.Order{}, res.Err
+		}
+		return res.Val.(domain.Order), nil
+	}
+}
+
+// refreshAsync обновляет устаревшую запись кэша в фоне. Повторные вызовы для
+// ключа, который уже обновляется, игнорируются; между репликами обновление
+// защищено блокировкой в Redis.
+func (p *Postgres) refreshAsync(id int, key string) {
+	if _, busy := p.refreshing.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	p.refreshWG.Add(1)
+	go func() {
+		defer p.refreshWG.Done()
+		defer p.refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+
+		token, locked, err := p.orderCache.TryLock(ctx, key, refreshTimeout)
+		if err != nil || !locked {
+			return
+		}
+		defer func() {
+			if err := p.orderCache.Unlock(ctx, key, token); err != nil {
+				p.logger.Warn("failed to release cache refresh lock", slog.String("key", key), slog.String("error", err.Error()))
+			}
+		}()
+
+		if _, err := p.loadAndCache(ctx, id, key); err != nil {
+			p.logger.Warn("failed to refresh cached order", slog.Int("id", id), slog.String("error", err.Error()))
+			return
+		}
+		p.logger.Debug("cached order refreshed", slog.Int("id", id))
+	}()
+}
+
+func (p *Postgres) cacheOrder(ctx context.Context, key string, o domain.Order) {
+	err := p.orderCache.Set(ctx, key, o)
+	if err != nil {
+		log.Printf("Failed to save in redis cache: %v", err)
+	} else {
+		log.Println("Order saved in redis cache")
 	}
+}
+
+// loadOrder читает заказ со всеми связанными данными из БД
+func (p *Postgres) loadOrder(ctx context.Context, id int) (domain.Order, error) {
+	var o domain.Order
+	var payment_id_fk int64
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		return domain.Order{}, e.Wrap("storage.pg.GetByUID.Begin", err)
 	}
 	defer func() {
-		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			p.logger.Error("failed to rollback transaction", slog.String("error", err.Error()))
 		}
 	}()
@@ -96,8 +198,8 @@ func (p *Postgres) GetByID(ctx context.Context, id int) (domain.Order, error) {
 	}
 
 	err = tx.QueryRow(ctx, `SELECT Transaction, Currency, Provider, Amount, PaymentDt, Bank, DeliveryCost,
-	GoodsTotal FROM payment WHERE id = $1`, payment_id_fk).Scan(&o.Payment.Transaction, &o.Payment.Currency, &o.Payment.Provider,
-		&o.Payment.Amount, &o.Payment.PaymentDt, &o.Payment.Bank, &o.Payment.DeliveryCost, &o.Payment.GoodsTotal)
+	GoodsTotal, CustomFee FROM payment WHERE id = $1`, payment_id_fk).Scan(&o.Payment.Transaction, &o.Payment.Currency, &o.Payment.Provider,
+		&o.Payment.Amount, &o.Payment.PaymentDt, &o.Payment.Bank, &o.Payment.DeliveryCost, &o.Payment.GoodsTotal, &o.Payment.CustomFee)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Order{}, e.ErrNotFound
@@ -145,29 +247,54 @@ func (p *Postgres) GetByID(ctx context.Context, id int) (domain.Order, error) {
 	if err := tx.Commit(ctx); err != nil {
 		return domain.Order{}, e.Wrap("storage.pg.GetByUID.Commit", err)
 	}
-	err = p.redisClient.Set(ctx, key, o, time.Minute*5)
-	if err != nil {
-		log.Printf("Failed to save in redis cache: %v", err)
-	} else {
-		log.Println("Order saved in redis cache")
-	}
 	return o, nil
 
 }
 func (p *Postgres) Create(ctx context.Context, o domain.Order) (int, error) {
-	var lastInsertId int
-	var itemsIds []int = []int{}
+	ids, err := p.CreateMany(ctx, []domain.Order{o})
+	if err != nil {
+		return 0, err
+	}
+	log.Println("Order successfull added in db")
+	return ids[0], nil
+}
 
+// CreateMany сохраняет заказы одной транзакцией: либо все, либо ни одного
+func (p *Postgres) CreateMany(ctx context.Context, orders []domain.Order) ([]int, error) {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
-		return 0, e.Wrap("storage.pg.CreateOrder.Begin", err)
+		return nil, e.Wrap("storage.pg.CreateOrder.Begin", err)
 	}
 	defer func() {
-		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			p.logger.Error("failed to rollback transaction", slog.String("error", err.Error()))
 		}
 	}()
 
+	ids := make([]int, 0, len(orders))
+	for _, o := range orders {
+		id, err := insertOrder(ctx, tx, o)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := saveMessageOffset(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, e.Wrap("storage.pg.CreateOrder.Commit", err)
+	}
+	return ids, nil
+}
+
+// insertOrder записывает заказ со всеми связанными данными в транзакции tx
+func insertOrder(ctx context.Context, tx pgx.Tx, o domain.Order) (int, error) {
+	var lastInsertId int
+	var itemsIds []int = []int{}
+
 	for _, item := range o.Items {
 		err := tx.QueryRow(ctx, `INSERT INTO items (ChrtID, Price, Rid, Name, Sale, Size, TotalPrice, NmID, Brand)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`, item.ChrtID, item.Price, item.Rid, item.Name, item.Sale, item.Size,
@@ -178,9 +305,9 @@ func (p *Postgres) Create(ctx context.Context, o domain.Order) (int, error) {
 		itemsIds = append(itemsIds, lastInsertId)
 	}
 
-	err = tx.QueryRow(ctx, `INSERT INTO payment (Transaction, Currency, Provider, Amount, PaymentDt, Bank, DeliveryCost,
-		 GoodsTotal) values ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, o.Payment.Transaction, o.Payment.Currency, o.Payment.Provider,
-		o.Payment.Amount, o.Payment.PaymentDt, o.Payment.Bank, o.Payment.DeliveryCost, o.Payment.GoodsTotal).Scan(&lastInsertId)
+	err := tx.QueryRow(ctx, `INSERT INTO payment (Transaction, Currency, Provider, Amount, PaymentDt, Bank, DeliveryCost,
+		 GoodsTotal, CustomFee) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`, o.Payment.Transaction, o.Payment.Currency, o.Payment.Provider,
+		o.Payment.Amount, o.Payment.PaymentDt, o.Payment.Bank, o.Payment.DeliveryCost, o.Payment.GoodsTotal, o.Payment.CustomFee).Scan(&lastInsertId)
 	if err != nil {
 		return 0, e.Wrap("storage.pg.CreateOrder", err)
 	}
@@ -210,17 +337,29 @@ func (p *Postgres) Create(ctx context.Context, o domain.Order) (int, error) {
 		}
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return 0, e.Wrap("storage.pg.CreateOrder.Commit", err)
-	}
-	log.Println("Order successfull added in db")
-
 	return orderIdFk, nil
+}
 
+// Check реализует health.Checker
+func (p *Postgres) Check(ctx context.Context) health.Result {
+	stat := p.pool.Stat()
+	res := health.Result{
+		Status: health.StatusUp,
+		Details: map[string]any{
+			"total_conns":    stat.TotalConns(),
+			"acquired_conns": stat.AcquiredConns(),
+			"idle_conns":     stat.IdleConns(),
+		},
+	}
+	if err := p.pool.Ping(ctx); err != nil {
+		res.Status = health.StatusDown
+		res.Error = err.Error()
+	}
+	return res
 }
 
 func (p *Postgres) CloseConnection() {
+	p.refreshWG.Wait()
 	p.pool.Close()
 	stat := p.pool.Stat()
 	if stat.AcquiredConns() > 0 {
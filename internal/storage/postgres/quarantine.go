@@ -0,0 +1,219 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"l0/internal/domain"
+	"l0/pkg/e"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// defaultQuarantineLimit размер страницы списка карантина по умолчанию
+const defaultQuarantineLimit = 50
+
+// quarantineReplayLease через сколько сообщение в replaying считается
+// брошенным (процесс упал во время обработки) и может быть захвачено снова
+const quarantineReplayLease = 5 * time.Minute
+
+const quarantineColumns = `id, topic, partition, "offset", message_key, payload, headers, stage, reason,
+	violations, attempts, status, discard_reason, order_id, created_at, updated_at, resolved_at`
+
+// Quarantine сохраняет необработанное сообщение. Повторная доставка того же
+// сообщения обновляет причину в существующей записи; обработанное или
+// отброшенное сообщение снова ожидает обработки.
+func (p *Postgres) Quarantine(ctx context.Context, m domain.QuarantinedMessage) (int64, error) {
+	if m.Headers == nil {
+		m.Headers = map[string]string{}
+	}
+	if m.Violations == nil {
+		m.Violations = []domain.Violation{}
+	}
+
+	var id int64
+	err := p.pool.QueryRow(ctx, `INSERT INTO quarantine (topic, partition, "offset", message_key, payload, headers,
+		stage, reason, violations, attempts) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (topic, partition, "offset") DO UPDATE SET stage = EXCLUDED.stage, reason = EXCLUDED.reason,
+			violations = EXCLUDED.violations, attempts = EXCLUDED.attempts, updated_at = now(),
+			status = CASE WHEN quarantine.status = 'replaying' THEN quarantine.status ELSE 'pending' END,
+			resolved_at = CASE WHEN quarantine.status = 'replaying' THEN quarantine.resolved_at END
+		RETURNING id`,
+		m.Topic, m.Partition, m.Offset, []byte(m.Key), []byte(m.Payload), m.Headers,
+		m.Stage, m.Reason, m.Violations, m.Attempts).Scan(&id)
+	if err != nil {
+		return 0, e.Wrap("storage.pg.Quarantine", err)
+	}
+	return id, nil
+}
+
+// ListQuarantined возвращает сообщения карантина, новые первыми
+func (p *Postgres) ListQuarantined(ctx context.Context, f domain.QuarantineFilter) ([]domain.QuarantinedMessage, error) {
+	var where []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != "" {
+		add("status = $%d", string(f.Status))
+	}
+	if f.Stage != "" {
+		add("stage = $%d", f.Stage)
+	}
+	if f.Topic != "" {
+		add("topic = $%d", f.Topic)
+	}
+
+	query := "SELECT " + quarantineColumns + " FROM quarantine"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultQuarantineLimit
+	}
+	args = append(args, limit, f.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, e.Wrap("storage.pg.ListQuarantined", err)
+	}
+	defer rows.Close()
+
+	result := []domain.QuarantinedMessage{}
+	for rows.Next() {
+		m, err := scanQuarantined(rows)
+		if err != nil {
+			return nil, e.Wrap("storage.pg.ListQuarantined.Scan", err)
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap("storage.pg.ListQuarantined.Rows.Err()", err)
+	}
+	return result, nil
+}
+
+func (p *Postgres) GetQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	m, err := scanQuarantined(p.pool.QueryRow(ctx, "SELECT "+quarantineColumns+" FROM quarantine WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.QuarantinedMessage{}, e.ErrNotFound
+		}
+		return domain.QuarantinedMessage{}, e.Wrap("storage.pg.GetQuarantined", err)
+	}
+	return m, nil
+}
+
+// UpdateQuarantinedPayload заменяет тело сообщения, ожидающего обработки.
+// Исправленное тело всегда JSON, поэтому content-type сообщения меняется.
+func (p *Postgres) UpdateQuarantinedPayload(ctx context.Context, id int64, payload []byte) error {
+	tag, err := p.pool.Exec(ctx, `UPDATE quarantine SET payload = $2,
+		headers = COALESCE(headers, '{}'::jsonb) || jsonb_build_object('content-type', 'application/json'::text),
+		updated_at = now()
+		WHERE id = $1 AND status = 'pending'`, id, payload)
+	if err != nil {
+		return e.Wrap("storage.pg.UpdateQuarantinedPayload", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return p.quarantineConflict(ctx, id)
+	}
+	return nil
+}
+
+// ClaimQuarantined переводит сообщение в состояние replaying, чтобы его не
+// обработали повторно параллельно, и возвращает его. Сообщение, застрявшее
+// в replaying дольше quarantineReplayLease, захватывается заново.
+func (p *Postgres) ClaimQuarantined(ctx context.Context, id int64) (domain.QuarantinedMessage, error) {
+	m, err := scanQuarantined(p.pool.QueryRow(ctx, `UPDATE quarantine SET status = 'replaying', updated_at = now()
+		WHERE id = $1 AND (status = 'pending'
+			OR status = 'replaying' AND updated_at < now() - make_interval(secs => $2))
+		RETURNING `+quarantineColumns, id, quarantineReplayLease.Seconds()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.QuarantinedMessage{}, p.quarantineConflict(ctx, id)
+		}
+		return domain.QuarantinedMessage{}, e.Wrap("storage.pg.ClaimQuarantined", err)
+	}
+	return m, nil
+}
+
+// ReleaseQuarantined возвращает сообщение в ожидание после неудачной
+// повторной обработки, сохраняя новую причину
+func (p *Postgres) ReleaseQuarantined(ctx context.Context, id int64, stage, reason string, violations []domain.Violation) error {
+	if violations == nil {
+		violations = []domain.Violation{}
+	}
+	_, err := p.pool.Exec(ctx, `UPDATE quarantine SET status = 'pending', stage = $2, reason = $3, violations = $4,
+		attempts = attempts + 1, updated_at = now() WHERE id = $1 AND status = 'replaying'`, id, stage, reason, violations)
+	if err != nil {
+		return e.Wrap("storage.pg.ReleaseQuarantined", err)
+	}
+	return nil
+}
+
+// MarkQuarantineReplayed отмечает сообщение успешно обработанным
+func (p *Postgres) MarkQuarantineReplayed(ctx context.Context, id int64, orderID int) error {
+	_, err := p.pool.Exec(ctx, `UPDATE quarantine SET status = 'replayed', order_id = NULLIF($2, 0), attempts = attempts + 1,
+		updated_at = now(), resolved_at = now() WHERE id = $1 AND status = 'replaying'`, id, orderID)
+	if err != nil {
+		return e.Wrap("storage.pg.MarkQuarantineReplayed", err)
+	}
+	return nil
+}
+
+// DiscardQuarantined отбрасывает сообщение с указанием причины
+func (p *Postgres) DiscardQuarantined(ctx context.Context, id int64, reason string) error {
+	tag, err := p.pool.Exec(ctx, `UPDATE quarantine SET status = 'discarded', discard_reason = $2,
+		updated_at = now(), resolved_at = now() WHERE id = $1 AND status = 'pending'`, id, reason)
+	if err != nil {
+		return e.Wrap("storage.pg.DiscardQuarantined", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return p.quarantineConflict(ctx, id)
+	}
+	return nil
+}
+
+// quarantineConflict объясняет, почему сообщение не удалось изменить:
+// его нет или оно уже не ожидает обработки
+func (p *Postgres) quarantineConflict(ctx context.Context, id int64) error {
+	var status string
+	err := p.pool.QueryRow(ctx, "SELECT status FROM quarantine WHERE id = $1", id).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return e.ErrNotFound
+		}
+		return e.Wrap("storage.pg.quarantineConflict", err)
+	}
+	return fmt.Errorf("message %d is %s: %w", id, status, e.ErrQuarantineResolved)
+}
+
+func scanQuarantined(row pgx.Row) (domain.QuarantinedMessage, error) {
+	var m domain.QuarantinedMessage
+	var key, payload []byte
+	var status string
+	var discardReason *string
+	var orderID *int64
+	err := row.Scan(&m.ID, &m.Topic, &m.Partition, &m.Offset, &key, &payload, &m.Headers, &m.Stage, &m.Reason,
+		&m.Violations, &m.Attempts, &status, &discardReason, &orderID, &m.CreatedAt, &m.UpdatedAt, &m.ResolvedAt)
+	if err != nil {
+		return domain.QuarantinedMessage{}, err
+	}
+	m.Key = string(key)
+	m.Payload = string(payload)
+	m.Status = domain.QuarantineStatus(status)
+	if discardReason != nil {
+		m.DiscardReason = *discardReason
+	}
+	if orderID != nil {
+		id := int(*orderID)
+		m.OrderID = &id
+	}
+	return m, nil
+}
@@ -0,0 +1,61 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"l0/internal/domain"
+	"l0/pkg/e"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SavePaymentConfirmation сохраняет подтверждение оплаты. Повторная доставка
+// того же подтверждения ничего не меняет.
+func (p *Postgres) SavePaymentConfirmation(ctx context.Context, c domain.PaymentConfirmation) error {
+	err := p.execWithOffset(ctx, `INSERT INTO payment_confirmations (order_uid, transaction, status, amount, currency,
+		confirmed_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (order_uid, transaction, status) DO NOTHING`,
+		c.OrderUID, c.Transaction, c.Status, c.Amount, c.Currency, c.ConfirmedAt)
+	if err != nil {
+		return e.Wrap("storage.pg.SavePaymentConfirmation", err)
+	}
+	return nil
+}
+
+// SaveDeliveryStatus сохраняет изменение статуса доставки. Повторная
+// доставка того же изменения ничего не меняет.
+func (p *Postgres) SaveDeliveryStatus(ctx context.Context, u domain.DeliveryStatusUpdate) error {
+	err := p.execWithOffset(ctx, `INSERT INTO delivery_statuses (order_uid, track_number, status, location, updated_at)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (order_uid, status, updated_at) DO NOTHING`,
+		u.OrderUID, u.TrackNumber, u.Status, u.Location, u.UpdatedAt)
+	if err != nil {
+		return e.Wrap("storage.pg.SaveDeliveryStatus", err)
+	}
+	return nil
+}
+
+// execWithOffset выполняет запрос в одной транзакции с отметкой сообщения
+// Kafka из ctx
+func (p *Postgres) execWithOffset(ctx context.Context, query string, args ...any) error {
+	if len(domain.MessageOffsetsFrom(ctx)) == 0 {
+		_, err := p.pool.Exec(ctx, query, args...)
+		return err
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			p.logger.Error("failed to rollback transaction", slog.String("error", err.Error()))
+		}
+	}()
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+	if err := saveMessageOffset(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"l0/pkg/e"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanBatch количество ключей, запрашиваемых за один SCAN
+const scanBatch = 500
+
+// DeletePrefix удаляет все ключи с префиксом. Используется SCAN, чтобы не
+// блокировать Redis и не затрагивать чужие ключи. Удаление идёт через
+// circuit breaker, как и остальные операции.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) (int64, error) {
+	pattern := prefix + "*"
+	var deleted atomic.Int64
+
+	err := r.do(func() error {
+		if cluster, ok := r.client.(*redis.ClusterClient); ok {
+			return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+				return scanDelete(ctx, client, pattern, &deleted)
+			})
+		}
+		return scanDelete(ctx, r.client, pattern, &deleted)
+	})
+	if err != nil {
+		return deleted.Load(), e.Wrap("storage.redis.DeletePrefix", err)
+	}
+	return deleted.Load(), nil
+}
+
+func scanDelete(ctx context.Context, client redis.Cmdable, pattern string, deleted *atomic.Int64) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanBatch).Result()
+		if err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+		if len(keys) > 0 {
+			// в кластере ключи одного батча могут лежать в разных слотах,
+			// поэтому удаляем по одному ключу в рамках pipeline
+			pipe := client.Pipeline()
+			cmds := make([]*redis.IntCmd, 0, len(keys))
+			for _, key := range keys {
+				cmds = append(cmds, pipe.Unlink(ctx, key))
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("unlink: %w", err)
+			}
+			for _, cmd := range cmds {
+				deleted.Add(cmd.Val())
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
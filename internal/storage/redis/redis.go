@@ -2,61 +2,351 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"l0/internal/cache"
 	"l0/internal/config"
-	"l0/internal/domain"
+	"l0/internal/health"
 	"l0/pkg/e"
 	"log/slog"
+	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Redis реализует cache.Backend и cache.Locker
 type Redis struct {
 	client redis.UniversalClient
-	logger *slog.Logger
+	// breaker отключает обращения к Redis после серии ошибок, чтобы
+	// недоступный кэш не добавлял таймауты к каждому запросу
+	breaker *cache.Breaker
+	logger  *slog.Logger
 }
 
 func NewRedis(config *config.RedisConfig, logger *slog.Logger) (*Redis, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("redis.NewRedis invalid config: %w", err)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("redis.NewRedis tls: %w", err)
+	}
+
 	client := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs:    config.Addrs,
-		Password: "",
-		DB:       0,
+		Addrs:            config.Addrs,
+		Username:         config.Username,
+		Password:         config.Password,
+		DB:               config.DBRedis,
+		MasterName:       config.SentinelMaster,
+		SentinelPassword: config.SentinelPassword,
+		IsClusterMode:    config.ClusterMode,
+		TLSConfig:        tlsConfig,
+		PoolSize:         config.PoolSize,
+		MinIdleConns:     config.MinIdleConns,
+		DialTimeout:      config.DialTimeout,
+		ReadTimeout:      config.ReadTimeout,
+		WriteTimeout:     config.WriteTimeout,
+		MaxRetries:       config.MaxRetries,
+		MinRetryBackoff:  config.MinRetryBackoff,
+		MaxRetryBackoff:  config.MaxRetryBackoff,
 	})
 
-	_, err := client.Ping(context.Background()).Result()
+	_, err = client.Ping(context.Background()).Result()
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("redis.NewRedis failed: %w", err)
 	}
 
+	breaker := cache.NewBreaker(cache.BreakerConfig{
+		FailureThreshold: config.BreakerFailures,
+		OpenTimeout:      config.BreakerOpenTimeout,
+		HalfOpenProbes:   config.BreakerHalfOpenProbes,
+	}, func(from, to cache.State) {
+		level := slog.LevelInfo
+		if to == cache.StateOpen {
+			level = slog.LevelWarn
+		}
+		logger.Log(context.Background(), level, "redis circuit breaker state changed",
+			slog.String("from", from.String()), slog.String("to", to.String()))
+	})
+
 	return &Redis{
-		client: client,
-		logger: logger,
+		client:  client,
+		breaker: breaker,
+		logger:  logger,
 	}, nil
 }
 
-func (r *Redis) Set(ctx context.Context, key string, value interface{}, exp time.Duration) error {
-	jsonValue, err := json.Marshal(value)
-	if err != nil {
-		return fmt.Errorf("ошибка при сохранении в кэш: %v", err)
+// newTLSConfig собирает tls.Config из файлов CA и клиентского сертификата
+func newTLSConfig(config *config.RedisConfig) (*tls.Config, error) {
+	if !config.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.TLSInsecureSkipVerify,
 	}
-	return r.client.Set(ctx, key, jsonValue, exp).Err()
+
+	if config.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
 }
 
-func (r *Redis) Get(ctx context.Context, key string, value *domain.Order) (string, error) {
-	result, err := r.client.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return "", fmt.Errorf("the requested key is not found: %v", err)
+func (r *Redis) Name() string { return "redis" }
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := r.do(func() error {
+		var err error
+		data, err = r.client.Get(ctx, key).Bytes()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("the requested key is not found: %w", e.ErrCacheMiss)
 	} else if err != nil {
-		return "", fmt.Errorf("just errror %v", err)
+		return nil, fmt.Errorf("just errror %w", err)
+	}
+	return data, nil
+}
+
+// GetMany читает ключи через pipeline: в кластере ключи могут лежать в разных
+// слотах, и MGET для них недоступен
+func (r *Redis) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	out := make([][]byte, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+
+	err := r.do(func() error {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(keys))
+		for i, k := range keys {
+			cmds[i] = pipe.Get(ctx, k)
+		}
+		// Exec возвращает только первую ошибку: если это промах, настоящие
+		// ошибки следующих команд видны лишь в самих командах
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, cmd := range cmds {
+			b, err := cmd.Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("get %s: %w", keys[i], err)
+			}
+			out[i] = b
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, e.Wrap("storage.redis.GetMany", err)
+	}
+	return out, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := r.do(func() error {
+		return r.client.Set(ctx, key, value, ttl).Err()
+	})
+	if err != nil {
+		return e.Wrap("storage.redis.Set", err)
+	}
+	return nil
+}
+
+func (r *Redis) SetMany(ctx context.Context, items []cache.Item, ttl time.Duration) error {
+	err := r.do(func() error {
+		pipe := r.client.Pipeline()
+		for _, it := range items {
+			pipe.Set(ctx, it.Key, it.Value, ttl)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return e.Wrap("storage.redis.SetMany", err)
+	}
+	return nil
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) (int64, error) {
+	var deleted int64
+	err := r.do(func() error {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.IntCmd, len(keys))
+		for i, k := range keys {
+			cmds[i] = pipe.Del(ctx, k)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.Val()
+		}
+		return nil
+	})
+	if err != nil {
+		return deleted, e.Wrap("storage.redis.Delete", err)
+	}
+	return deleted, nil
+}
+
+func (r *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var ttl time.Duration
+	err := r.do(func() error {
+		var err error
+		ttl, err = r.client.PTTL(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return 0, e.Wrap("storage.redis.TTL", err)
+	}
+	return ttl, nil
+}
+
+// Tag добавляет ключи в множества тегов. Срок жизни множества обновляется
+// до срока жизни последней записи, чтобы теги не переживали данные надолго.
+func (r *Redis) Tag(ctx context.Context, tags []string, keys []string, ttl time.Duration) error {
+	members := make([]interface{}, len(keys))
+	for i, k := range keys {
+		members[i] = k
 	}
 
-	if err := json.Unmarshal([]byte(result), value); err != nil {
-		return "", fmt.Errorf("could not unmarshal(cache): %v", err)
+	err := r.do(func() error {
+		pipe := r.client.Pipeline()
+		for _, t := range tags {
+			pipe.SAdd(ctx, t, members...)
+			if ttl > 0 {
+				pipe.Expire(ctx, t, ttl)
+			}
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return e.Wrap("storage.redis.Tag", err)
+	}
+	return nil
+}
+
+func (r *Redis) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	var deleted int64
+	for _, t := range tags {
+		var keys []string
+		err := r.do(func() error {
+			var err error
+			keys, err = r.client.SMembers(ctx, t).Result()
+			return err
+		})
+		if err != nil {
+			return deleted, e.Wrap("storage.redis.InvalidateTags", err)
+		}
+
+		n, err := r.Delete(ctx, append(keys, t)...)
+		if err != nil {
+			return deleted, e.Wrap("storage.redis.InvalidateTags", err)
+		}
+		// сам тег тоже удалён, его в счёт не включаем
+		if n > 0 {
+			n--
+		}
+		deleted += n
 	}
+	return deleted, nil
+}
 
-	return result, nil
+// unlockScript удаляет блокировку, только если она принадлежит токену
+var unlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// TryLock захватывает короткую блокировку на ключ (SET NX), чтобы только
+// одна реплика сервиса обновляла устаревшую запись. Значение блокировки —
+// токен владельца для Unlock.
+func (r *Redis) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := cache.NewLockToken()
+	var ok bool
+	err := r.do(func() error {
+		var err error
+		ok, err = r.client.SetNX(ctx, key+":lock", token, ttl).Result()
+		return err
+	})
+	if err != nil {
+		return "", false, e.Wrap("storage.redis.TryLock", err)
+	}
+	if !ok {
+		return "", false, nil
+	}
+	return token, true, nil
+}
+
+// Unlock снимает блокировку, захваченную TryLock, если она не истекла и не
+// перешла к другому владельцу
+func (r *Redis) Unlock(ctx context.Context, key, token string) error {
+	err := r.do(func() error {
+		return unlockScript.Run(ctx, r.client, []string{key + ":lock"}, token).Err()
+	})
+	if err != nil {
+		return e.Wrap("storage.redis.Unlock", err)
+	}
+	return nil
+}
+
+// do выполняет команду Redis через circuit breaker. Промах и отмена
+// запроса клиентом не считаются отказом Redis.
+func (r *Redis) do(fn func() error) error {
+	return r.breaker.Execute(fn, func(err error) bool {
+		return !errors.Is(err, redis.Nil) && !errors.Is(err, context.Canceled)
+	})
+}
+
+// BreakerState возвращает состояние circuit breaker
+func (r *Redis) BreakerState() cache.BreakerSnapshot {
+	return r.breaker.Snapshot()
+}
+
+// Check реализует health.Checker. Недоступность Redis не делает сервис
+// неработоспособным — заказы читаются из Postgres, поэтому статус degraded.
+func (r *Redis) Check(ctx context.Context) health.Result {
+	breaker := r.breaker.Snapshot()
+	res := health.Result{
+		Status:  health.StatusUp,
+		Details: map[string]any{"circuit_breaker": breaker},
+	}
+	if breaker.State != cache.StateClosed.String() {
+		res.Status = health.StatusDegraded
+	}
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		res.Status = health.StatusDegraded
+		res.Error = err.Error()
+	}
+	return res
 }
 
 func (r *Redis) Close() error {
@@ -5,7 +5,12 @@ import (
 	"fmt"
 )
 
-var ErrNotFound = errors.New("order not found")
+var (
+	ErrNotFound  = errors.New("order not found")
+	ErrCacheMiss = errors.New("cache miss")
+	// ErrQuarantineResolved сообщение из карантина уже повторно обработано или отброшено
+	ErrQuarantineResolved = errors.New("quarantined message already resolved")
+)
 
 func Wrap(message string, err error) error {
 	return fmt.Errorf("%s: %w", message, err)